@@ -8,9 +8,13 @@ import (
 	"time"
 
 	"pulse_agent/internal/agent"
+	"pulse_agent/internal/commands"
 	"pulse_agent/internal/config"
+	"pulse_agent/internal/docker"
+	"pulse_agent/internal/runtime"
 	"pulse_agent/internal/scheduler"
 	"pulse_agent/internal/sender"
+	"pulse_agent/internal/terminal"
 	"pulse_agent/internal/ws"
 	"pulse_agent/pkg/logger"
 )
@@ -47,10 +51,21 @@ func main() {
 	// Attach server ID to config
 	cfg.ServerID = serverID
 
+	// Shared by metrics collection and remote container actions. Docker
+	// and Podman retry in the background if the daemon is not up yet.
+	rt, err := runtime.Detect(cfg)
+	if err != nil {
+		logger.Warn("Container runtime not available, system metrics only: %v", err)
+	}
+	// Container actions need the Docker API (Docker or Podman)
+	dockerClient, _ := rt.(*docker.Client)
+	actions := commands.New(dockerClient, cfg)
+	recordings := terminal.NewRecordings(cfg.Terminal.Recording, sender.New(cfg).SendRecording)
+
 	go func() {
 		for {
 			logger.Info("Connecting agent terminal WS...")
-			err := ws.ConnectAgentWS(ctx, cfg, serverID)
+			err := ws.ConnectAgentWS(ctx, cfg, serverID, actions, recordings)
 			if err != nil {
 				logger.Warn("Agent WS disconnected: %v", err)
 			}
@@ -60,7 +75,7 @@ func main() {
 		}
 	}()
 	// Start scheduler (metrics collection + sending)
-	sched := scheduler.New(cfg)
+	sched := scheduler.New(cfg, rt)
 	go sched.Start()
 
 	// Graceful shutdown handling
@@ -72,6 +87,16 @@ func main() {
 
 	sched.Stop()
 	time.Sleep(1 * time.Second)
+	if rt != nil {
+		rt.Close()
+	}
+
+	// Forwarded logs still queued are shipped once more
+	select {
+	case <-sched.LogsDone():
+	case <-time.After(docker.LogsFinalFlushTimeout):
+		logger.Warn("Timed out flushing container logs")
+	}
 
 	logger.Info("Agent stopped gracefully")
 }
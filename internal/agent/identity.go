@@ -18,9 +18,14 @@ func HashAPIKey(key string) string {
 	return hex.EncodeToString(sum[:])
 }
 
-func identityPath() string {
+// DataDir is where the agent keeps local state between restarts
+func DataDir() string {
 	home, _ := os.UserHomeDir()
-	return filepath.Join(home, ".pulse", "identity.json")
+	return filepath.Join(home, ".pulse")
+}
+
+func identityPath() string {
+	return filepath.Join(DataDir(), "identity.json")
 }
 
 func SaveServerIdentity(serverID, apiKey string) error {
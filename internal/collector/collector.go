@@ -7,27 +7,54 @@ import (
 
 	"pulse_agent/internal/config"
 	"pulse_agent/internal/docker"
+	"pulse_agent/internal/events"
+	"pulse_agent/internal/kubelet"
 	"pulse_agent/internal/models"
+	"pulse_agent/internal/network"
+	"pulse_agent/internal/runtime"
+	"pulse_agent/internal/services"
 	"pulse_agent/internal/system"
 	"pulse_agent/pkg/logger"
 )
 
 type Collector struct {
 	cfg          *config.Config
-	dockerClient *docker.Client
+	runtime      runtime.Runtime
+	dockerClient *docker.Client  // Docker-API runtimes only
+	kubelet      *kubelet.Client // Kubernetes node mode only
 	systemClient *system.Collector
+	kernel       *system.KernelCollector
+	sockets      *network.SocketCollector
+	sensors      *system.SensorCollector
+	services     *services.Collector
+	events       *events.Buffer
+	logs         *docker.LogForwarder // nil unless log forwarding runs
 }
 
-func New(cfg *config.Config) *Collector {
-	dockerClient, err := docker.NewClient()
-	if err != nil {
-		logger.Warn("Docker not available, system metrics only")
+// New takes the shared container runtime, which may be nil when none is
+// configured; the caller closes it
+func New(cfg *config.Config, rt runtime.Runtime) *Collector {
+	// Events and disk usage need the Docker API (Docker or Podman)
+	dockerClient, _ := rt.(*docker.Client)
+	kubeletClient, _ := rt.(*kubelet.Client)
+
+	eventBuf := events.NewBuffer(cfg.EventBufferSize)
+	if dockerClient != nil {
+		dockerClient.WatchEvents(eventBuf)
+		dockerClient.CollectDiskUsage(cfg.Docker.DiskUsageInterval)
 	}
 
 	return &Collector{
 		cfg:          cfg,
+		runtime:      rt,
 		dockerClient: dockerClient,
+		kubelet:      kubeletClient,
 		systemClient: system.NewCollector(),
+		kernel:       system.NewKernelCollector(),
+		sockets:      network.NewSocketCollector(eventBuf),
+		sensors:      system.NewSensorCollector(),
+		services:     services.New(cfg, eventBuf),
+		events:       eventBuf,
 	}
 }
 
@@ -46,22 +73,93 @@ func (c *Collector) Collect(ctx context.Context) (*models.Payload, error) {
 		payload.System = systemStats
 	}
 
-	// Collect Docker stats if available
-	if c.dockerClient != nil && c.dockerClient.IsAvailable() {
-		containers, err := c.dockerClient.GetContainerStats(ctx)
+	// Collect kernel counters
+	kernelStats, err := c.kernel.GetKernelStats(ctx)
+	if err != nil {
+		logger.Error("Failed to collect kernel stats: %v", err)
+	} else {
+		payload.Kernel = kernelStats
+	}
+
+	// Collect socket summary and listening ports
+	socketStats, err := c.sockets.GetSocketStats(ctx)
+	if err != nil {
+		logger.Error("Failed to collect socket stats: %v", err)
+	} else {
+		payload.Sockets = socketStats
+	}
+
+	// Collect hardware sensors (empty on VMs and most containers)
+	sensors, err := c.sensors.GetSensorStats(ctx)
+	if err != nil {
+		logger.Debug("Failed to collect sensors: %v", err)
+	} else {
+		payload.Sensors = sensors
+	}
+
+	// Collect container stats if a runtime is available
+	if c.runtime != nil && c.runtime.IsAvailable() {
+		payload.RuntimeAvailable = true
+		payload.ContainerRuntime = c.runtime.Name()
+		payload.DockerAvailable = payload.ContainerRuntime == config.RuntimeDocker
+		containers, err := c.runtime.GetContainerStats(ctx)
 		if err != nil {
 			logger.Error("Failed to collect container stats: %v", err)
 		} else {
 			payload.Containers = containers
 			payload.ContainerCount = len(containers)
+			payload.ComposeProjects = docker.GroupByCompose(containers)
+		}
+
+		if c.dockerClient != nil {
+			payload.DockerDisk = c.dockerClient.TakeDiskUsage()
+		}
+		if c.kubelet != nil {
+			payload.Pods = c.kubelet.TakePods()
 		}
 	}
 
+	// Collect watched services
+	payload.Services = c.services.Collect(ctx)
+
+	payload.Events = c.events.Drain()
+
 	return payload, nil
 }
 
+// StartLogForwarding follows selected container logs when enabled
+func (c *Collector) StartLogForwarding(ship docker.LogShipper) {
+	if !c.cfg.Docker.Logs.Enabled || c.dockerClient == nil {
+		return
+	}
+	c.logs = c.dockerClient.ForwardLogs(c.cfg.Docker.Logs, ship)
+}
+
+// LogsDone is closed once log forwarding shipped its last lines after
+// the runtime was closed, or right away when logs are not forwarded
+func (c *Collector) LogsDone() <-chan struct{} {
+	if c.logs == nil {
+		done := make(chan struct{})
+		close(done)
+		return done
+	}
+	return c.logs.Done()
+}
+
+// RequeueEvents keeps events from a payload that failed to send so
+// they go out with the next one
+func (c *Collector) RequeueEvents(events []models.Event) {
+	c.events.Requeue(events)
+}
+
 func (c *Collector) Close() {
-	if c.dockerClient != nil {
-		c.dockerClient.Close()
+	c.services.Close()
+}
+
+// RequeueDiskUsage keeps a disk usage summary from a payload that failed
+// to send, unless a newer one was collected meanwhile
+func (c *Collector) RequeueDiskUsage(metric *models.DockerDiskMetric) {
+	if c.dockerClient != nil && metric != nil {
+		c.dockerClient.RequeueDiskUsage(metric)
 	}
 }
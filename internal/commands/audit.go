@@ -0,0 +1,84 @@
+// internal/commands/audit.go
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/agent"
+	"pulse_agent/pkg/logger"
+)
+
+// The audit log is rotated once to audit.log.1 when it grows past this
+const auditMaxSize = 10 * 1024 * 1024
+
+type auditEntry struct {
+	Time        time.Time `json:"time"`
+	RequestID   string    `json:"request_id"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Action      string    `json:"action"`
+	Container   string    `json:"container,omitempty"`
+	ContainerID string    `json:"container_id,omitempty"`
+	Command     []string  `json:"command,omitempty"` // for "run"
+	OK          bool      `json:"ok"`
+	Denied      bool      `json:"denied,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// auditLog appends one JSON line per requested action to a file in the
+// agent data directory
+type auditLog struct {
+	mu   sync.Mutex
+	path string
+}
+
+func openAuditLog() *auditLog {
+	return &auditLog{path: filepath.Join(agent.DataDir(), "audit.log")}
+}
+
+func (a *auditLog) recordAction(req ContainerActionRequest, result ContainerActionResult) {
+	a.record(auditEntry{
+		RequestID:   req.RequestID,
+		RequestedBy: req.RequestedBy,
+		Action:      req.Action,
+		Container:   req.Container,
+		ContainerID: result.ContainerID,
+		OK:          result.OK,
+		Denied:      result.Denied,
+		Error:       result.Error,
+	})
+}
+
+func (a *auditLog) record(entry auditEntry) {
+	entry.Time = time.Now()
+	data, _ := json.Marshal(entry)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.append(append(data, '\n')); err != nil {
+		logger.Warn("Failed to write audit log: %v", err)
+	}
+}
+
+func (a *auditLog) append(line []byte) error {
+	if info, err := os.Stat(a.path); err == nil && info.Size() > auditMaxSize {
+		_ = os.Rename(a.path, a.path+".1")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(line)
+	return err
+}
@@ -0,0 +1,91 @@
+// internal/commands/commands.go
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/docker"
+	"pulse_agent/pkg/logger"
+)
+
+// Extra time on top of the stop timeout for the daemon to answer
+const actionGrace = 30 * time.Second
+
+// ContainerActionRequest is sent by the backend as "container:action"
+type ContainerActionRequest struct {
+	RequestID   string `json:"request_id"`
+	Action      string `json:"action"`    // start | stop | restart | pause | unpause | remove
+	Container   string `json:"container"` // name or ID
+	RequestedBy string `json:"requested_by,omitempty"`
+}
+
+// ContainerActionResult is sent back as "container:action:result"
+type ContainerActionResult struct {
+	RequestID     string `json:"request_id"`
+	Action        string `json:"action"`
+	Container     string `json:"container"`
+	ContainerID   string `json:"container_id,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	OK            bool   `json:"ok"`
+	Denied        bool   `json:"denied,omitempty"`
+	Error         string `json:"error,omitempty"`
+	DurationMs    int64  `json:"duration_ms"`
+}
+
+// Executor runs actions requested by the backend and records each one
+// in the local audit log, whether it was allowed or not
+type Executor struct {
+	docker   *docker.Client
+	timeout  time.Duration
+	terminal config.TerminalConfig
+	audit    *auditLog
+}
+
+// New returns an executor; dockerClient may be nil, in which case every
+// container action fails
+func New(dockerClient *docker.Client, cfg *config.Config) *Executor {
+	return &Executor{
+		docker:   dockerClient,
+		timeout:  cfg.Docker.Actions.StopTimeout + actionGrace,
+		terminal: cfg.Terminal,
+		audit:    openAuditLog(),
+	}
+}
+
+func (e *Executor) ContainerAction(ctx context.Context, req ContainerActionRequest) ContainerActionResult {
+	start := time.Now()
+	result := ContainerActionResult{
+		RequestID: req.RequestID,
+		Action:    req.Action,
+		Container: req.Container,
+	}
+
+	var err error
+	if e.docker == nil {
+		err = errors.New("docker not available")
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+
+		var target docker.ContainerRef
+		target, err = e.docker.RunAction(ctx, req.Action, req.Container)
+		result.ContainerID = target.ID
+		result.ContainerName = target.Name
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		result.Denied = errors.Is(err, docker.ErrActionDenied)
+		logger.Warn("Container action %s on %s failed: %v", req.Action, req.Container, err)
+	} else {
+		result.OK = true
+		logger.Info("Container action %s on %s done in %dms", req.Action, req.Container, result.DurationMs)
+	}
+
+	e.audit.recordAction(req, result)
+	return result
+}
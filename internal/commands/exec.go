@@ -0,0 +1,74 @@
+// internal/commands/exec.go
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pulse_agent/internal/docker"
+	"pulse_agent/internal/terminal"
+	"pulse_agent/pkg/logger"
+)
+
+const execTimeout = 30 * time.Second
+
+// ContainerExecRequest opens a shell inside a container instead of on
+// the host, for a "terminal:open" naming a container
+type ContainerExecRequest struct {
+	RequestID   string `json:"request_id"`
+	Container   string `json:"container"` // name or ID
+	Rows        uint16 `json:"rows,omitempty"`
+	Cols        uint16 `json:"cols,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"`
+}
+
+// ContainerExec starts an exec session and audits it as the "exec"
+// action. The terminal is nil unless the result is OK.
+func (e *Executor) ContainerExec(ctx context.Context, req ContainerExecRequest) (terminal.Terminal, ContainerActionResult) {
+	start := time.Now()
+	result := ContainerActionResult{
+		RequestID: req.RequestID,
+		Action:    "exec",
+		Container: req.Container,
+	}
+
+	var (
+		session *docker.ExecSession
+		err     error
+	)
+	if e.docker == nil {
+		err = errors.New("docker not available")
+	} else {
+		ctx, cancel := context.WithTimeout(ctx, execTimeout)
+		defer cancel()
+
+		var target docker.ContainerRef
+		session, target, err = e.docker.Exec(ctx, req.Container, req.Rows, req.Cols)
+		result.ContainerID = target.ID
+		result.ContainerName = target.Name
+	}
+
+	result.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		result.Denied = errors.Is(err, docker.ErrActionDenied)
+		logger.Warn("Exec in %s failed: %v", req.Container, err)
+	} else {
+		result.OK = true
+		logger.Info("Exec session opened in %s", req.Container)
+	}
+
+	e.audit.recordAction(ContainerActionRequest{
+		RequestID:   req.RequestID,
+		Action:      result.Action,
+		Container:   req.Container,
+		RequestedBy: req.RequestedBy,
+	}, result)
+
+	// Not a nil *ExecSession in a non-nil interface
+	if session == nil {
+		return nil, result
+	}
+	return session, result
+}
@@ -0,0 +1,73 @@
+// internal/commands/run.go
+package commands
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"pulse_agent/internal/terminal"
+	"pulse_agent/pkg/logger"
+)
+
+// RunCommandRequest is sent by the backend as "terminal:run" to run one
+// allowlisted command without a shell
+type RunCommandRequest struct {
+	RequestID   string   `json:"request_id"`
+	Command     string   `json:"command"` // name, resolved in the shells' PATH
+	Args        []string `json:"args,omitempty"`
+	RequestedBy string   `json:"requested_by,omitempty"`
+}
+
+// RunCommandResult is sent back as "terminal:run:result"
+type RunCommandResult struct {
+	RequestID  string   `json:"request_id"`
+	Command    string   `json:"command"`
+	Args       []string `json:"args,omitempty"`
+	OK         bool     `json:"ok"`
+	Denied     bool     `json:"denied,omitempty"`
+	Error      string   `json:"error,omitempty"`
+	ExitCode   int      `json:"exit_code"`
+	Signal     string   `json:"signal,omitempty"`
+	Output     string   `json:"output"`
+	Truncated  bool     `json:"truncated,omitempty"`
+	DurationMs int64    `json:"duration_ms"`
+}
+
+// RunCommand runs a command allowed by the terminal policy and audits it
+// as the "run" action. OK means it ran; ExitCode tells how it went.
+func (e *Executor) RunCommand(ctx context.Context, req RunCommandRequest) RunCommandResult {
+	start := time.Now()
+	result := RunCommandResult{
+		RequestID: req.RequestID,
+		Command:   req.Command,
+		Args:      req.Args,
+	}
+
+	run, err := terminal.RunCommand(ctx, e.terminal, req.Command, req.Args)
+	result.ExitCode = run.Code
+	result.Signal = run.Signal
+	result.Output = run.Output
+	result.Truncated = run.Truncated
+	result.DurationMs = time.Since(start).Milliseconds()
+
+	if err != nil {
+		result.Error = err.Error()
+		result.Denied = errors.Is(err, terminal.ErrCommandDenied)
+		logger.Warn("Command %s requested by %s failed: %v", req.Command, req.RequestedBy, err)
+	} else {
+		result.OK = true
+		logger.Info("Command %s exited with %d in %dms", req.Command, run.Code, result.DurationMs)
+	}
+
+	e.audit.record(auditEntry{
+		RequestID:   req.RequestID,
+		RequestedBy: req.RequestedBy,
+		Action:      "run",
+		Command:     append([]string{req.Command}, req.Args...),
+		OK:          result.OK,
+		Denied:      result.Denied,
+		Error:       result.Error,
+	})
+	return result
+}
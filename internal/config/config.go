@@ -6,6 +6,7 @@ import (
 	"os"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -20,6 +21,16 @@ type Config struct {
 	Environment string
 	OS          string
 	Arch        string
+
+	// Watched services: systemd units or process patterns
+	WatchServices []string
+
+	// Events kept while the backend is unreachable
+	EventBufferSize int
+
+	Runtime  RuntimeConfig
+	Docker   DockerConfig
+	Terminal TerminalConfig
 }
 
 func Load() (*Config, error) {
@@ -56,6 +67,23 @@ func Load() (*Config, error) {
 	}
 	cfg.Interval = interval
 
+	// Watched services (e.g. "nginx,unit:docker.service,process:redis-server,regex:java.*kafka")
+	cfg.WatchServices = getEnvList("AGENT_WATCH_SERVICES")
+
+	if cfg.EventBufferSize, err = getEnvInt("AGENT_EVENT_BUFFER_SIZE", 1000); err != nil {
+		return nil, err
+	}
+
+	if cfg.Runtime, err = loadRuntimeConfig(); err != nil {
+		return nil, err
+	}
+	if cfg.Docker, err = loadDockerConfig(); err != nil {
+		return nil, err
+	}
+	if cfg.Terminal, err = loadTerminalConfig(); err != nil {
+		return nil, err
+	}
+
 	// Validate backend URL
 	if cfg.BackendURL == "" {
 		return nil, errors.New("AGENT_BACKEND_URL is required")
@@ -73,6 +101,55 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: must be an integer", key)
+	}
+	return value, nil
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return value, nil
+}
+
+func getEnvList(key string) []string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func parseInterval(value string) (time.Duration, error) {
 	// Try duration format first: "1s", "500ms", "1m"
 	if d, err := time.ParseDuration(value); err == nil {
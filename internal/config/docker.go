@@ -0,0 +1,269 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+)
+
+const (
+	DockerStatsStream = "stream"
+	DockerStatsPoll   = "poll"
+)
+
+// DockerActions are the container actions the backend may request
+var DockerActions = []string{"start", "stop", "restart", "pause", "unpause", "remove"}
+
+type DockerConfig struct {
+	// "stream" keeps a stats subscription per running container,
+	// "poll" requests a one-shot sample every cycle
+	StatsMode string
+
+	// Concurrent ContainerStats requests per collection cycle
+	StatsWorkers int
+	// Timeout for a single container's stats request
+	StatsTimeout time.Duration
+
+	// How often "system df" is collected; 0 disables it
+	DiskUsageInterval time.Duration
+
+	Filter DockerFilterConfig
+
+	// Container labels copied into each container metric
+	Labels []string
+
+	Logs DockerLogsConfig
+
+	Actions DockerActionsConfig
+
+	Exec DockerExecConfig
+}
+
+// DockerFilterConfig selects which containers are reported. A container
+// must match every include filter that is set and no exclude filter.
+type DockerFilterConfig struct {
+	IncludeNames  *regexp.Regexp
+	ExcludeNames  *regexp.Regexp
+	IncludeImages []string // image name prefixes
+	ExcludeImages []string
+	IncludeLabels []string // "key=value" or "key"
+	ExcludeLabels []string
+	States        []string // e.g. running, paused, exited
+	SkipStopped   bool
+}
+
+// DockerLogsConfig selects containers whose logs are forwarded. A
+// container is followed if it matches any of the selectors.
+type DockerLogsConfig struct {
+	Enabled bool
+	Names   *regexp.Regexp // container name
+	Images  []string       // image name prefixes
+	Label   string         // "key=value" or "key"
+
+	// Lines matching this pattern are appended to the previous record
+	Multiline *regexp.Regexp
+
+	BatchSize     int
+	FlushInterval time.Duration
+	RateLimit     int // lines per second across all containers
+}
+
+// DockerActionsConfig is the local policy for container actions requested
+// over the agent WebSocket. Nothing is allowed unless listed in Allowed.
+// When Names or Labels are set, the container must match one of them.
+type DockerActionsConfig struct {
+	Allowed []string       // subset of DockerActions
+	Names   *regexp.Regexp // whole container name
+	Labels  []string       // "key=value" or "key"
+
+	// Grace period before stop and restart kill the container
+	StopTimeout time.Duration
+}
+
+// DockerExecConfig is the local policy for interactive exec sessions
+// requested over the agent WebSocket. Only containers matching Names or
+// one of Labels can be entered; with neither set exec is disabled.
+type DockerExecConfig struct {
+	Names  *regexp.Regexp // whole container name
+	Labels []string       // "key=value" or "key"
+
+	// Shell started in the container; empty picks bash when the image
+	// has it, otherwise sh
+	Shell string
+	// User the shell runs as; empty keeps the container's user
+	User string
+}
+
+// Enabled reports whether any container may be entered
+func (c DockerExecConfig) Enabled() bool {
+	return c.Names != nil || len(c.Labels) > 0
+}
+
+func loadDockerConfig() (DockerConfig, error) {
+	var (
+		cfg DockerConfig
+		err error
+	)
+
+	cfg.StatsMode = getEnv("AGENT_DOCKER_STATS_MODE", DockerStatsStream)
+	if cfg.StatsMode != DockerStatsStream && cfg.StatsMode != DockerStatsPoll {
+		return cfg, fmt.Errorf("invalid AGENT_DOCKER_STATS_MODE: must be %q or %q", DockerStatsStream, DockerStatsPoll)
+	}
+
+	if cfg.StatsWorkers, err = getEnvInt("AGENT_DOCKER_STATS_WORKERS", 8); err != nil {
+		return cfg, err
+	}
+	if cfg.StatsWorkers < 1 {
+		return cfg, fmt.Errorf("invalid AGENT_DOCKER_STATS_WORKERS: must be at least 1")
+	}
+
+	if cfg.StatsTimeout, err = getEnvDuration("AGENT_DOCKER_STATS_TIMEOUT", 5*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.StatsTimeout <= 0 {
+		return cfg, fmt.Errorf("invalid AGENT_DOCKER_STATS_TIMEOUT: must be positive")
+	}
+
+	if cfg.DiskUsageInterval, err = getEnvDuration("AGENT_DOCKER_DISK_USAGE_INTERVAL", 5*time.Minute); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Filter, err = loadDockerFilterConfig(); err != nil {
+		return cfg, err
+	}
+	cfg.Labels = getEnvList("AGENT_DOCKER_LABELS")
+
+	if cfg.Logs, err = loadDockerLogsConfig(); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Actions, err = loadDockerActionsConfig(); err != nil {
+		return cfg, err
+	}
+
+	if cfg.Exec.Names, err = getEnvNamePolicy("AGENT_DOCKER_EXEC_NAMES"); err != nil {
+		return cfg, err
+	}
+	cfg.Exec.Labels = getEnvList("AGENT_DOCKER_EXEC_LABELS")
+	cfg.Exec.Shell = getEnv("AGENT_DOCKER_EXEC_SHELL", "")
+	cfg.Exec.User = getEnv("AGENT_DOCKER_EXEC_USER", "")
+
+	return cfg, nil
+}
+
+func loadDockerFilterConfig() (DockerFilterConfig, error) {
+	var (
+		cfg DockerFilterConfig
+		err error
+	)
+
+	if cfg.IncludeNames, err = getEnvRegexp("AGENT_DOCKER_INCLUDE_NAMES"); err != nil {
+		return cfg, err
+	}
+	if cfg.ExcludeNames, err = getEnvRegexp("AGENT_DOCKER_EXCLUDE_NAMES"); err != nil {
+		return cfg, err
+	}
+
+	cfg.IncludeImages = getEnvList("AGENT_DOCKER_INCLUDE_IMAGES")
+	cfg.ExcludeImages = getEnvList("AGENT_DOCKER_EXCLUDE_IMAGES")
+	cfg.IncludeLabels = getEnvList("AGENT_DOCKER_INCLUDE_LABELS")
+	cfg.ExcludeLabels = getEnvList("AGENT_DOCKER_EXCLUDE_LABELS")
+	cfg.States = getEnvList("AGENT_DOCKER_STATES")
+	cfg.SkipStopped = getEnvBool("AGENT_DOCKER_SKIP_STOPPED", false)
+	if cfg.SkipStopped && len(cfg.States) > 0 && !slices.Contains(cfg.States, "running") {
+		return cfg, fmt.Errorf("invalid AGENT_DOCKER_STATES: must include running when AGENT_DOCKER_SKIP_STOPPED is set")
+	}
+
+	return cfg, nil
+}
+
+func loadDockerLogsConfig() (DockerLogsConfig, error) {
+	var (
+		cfg DockerLogsConfig
+		err error
+	)
+
+	cfg.Enabled = getEnvBool("AGENT_DOCKER_LOGS_ENABLED", false)
+	cfg.Images = getEnvList("AGENT_DOCKER_LOGS_IMAGES")
+	cfg.Label = getEnv("AGENT_DOCKER_LOGS_LABEL", "pulse.logs=true")
+
+	if cfg.Names, err = getEnvRegexp("AGENT_DOCKER_LOGS_NAMES"); err != nil {
+		return cfg, err
+	}
+
+	// Default: indented lines, Java "Caused by:" and "... N more"
+	multiline := getEnv("AGENT_DOCKER_LOGS_MULTILINE", `^(\s|Caused by:|\.\.\. \d+ more)`)
+	if cfg.Multiline, err = regexp.Compile(multiline); err != nil {
+		return cfg, fmt.Errorf("invalid AGENT_DOCKER_LOGS_MULTILINE: %w", err)
+	}
+
+	if cfg.BatchSize, err = getEnvInt("AGENT_DOCKER_LOGS_BATCH_SIZE", 500); err != nil {
+		return cfg, err
+	}
+	if cfg.FlushInterval, err = getEnvDuration("AGENT_DOCKER_LOGS_FLUSH_INTERVAL", 5*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.RateLimit, err = getEnvInt("AGENT_DOCKER_LOGS_RATE_LIMIT", 1000); err != nil {
+		return cfg, err
+	}
+	if cfg.BatchSize < 1 || cfg.RateLimit < 1 || cfg.FlushInterval <= 0 {
+		return cfg, fmt.Errorf("AGENT_DOCKER_LOGS_BATCH_SIZE, AGENT_DOCKER_LOGS_RATE_LIMIT and AGENT_DOCKER_LOGS_FLUSH_INTERVAL must be positive")
+	}
+
+	return cfg, nil
+}
+
+func loadDockerActionsConfig() (DockerActionsConfig, error) {
+	var (
+		cfg DockerActionsConfig
+		err error
+	)
+
+	cfg.Allowed = getEnvList("AGENT_DOCKER_ACTIONS")
+	for _, action := range cfg.Allowed {
+		if !slices.Contains(DockerActions, action) {
+			return cfg, fmt.Errorf("invalid AGENT_DOCKER_ACTIONS: unknown action %q", action)
+		}
+	}
+
+	if cfg.Names, err = getEnvNamePolicy("AGENT_DOCKER_ACTIONS_NAMES"); err != nil {
+		return cfg, err
+	}
+	cfg.Labels = getEnvList("AGENT_DOCKER_ACTIONS_LABELS")
+
+	if cfg.StopTimeout, err = getEnvDuration("AGENT_DOCKER_ACTIONS_STOP_TIMEOUT", 10*time.Second); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// getEnvRegexp returns nil when the variable is unset
+func getEnvRegexp(key string) (*regexp.Regexp, error) {
+	raw := getEnv(key, "")
+	if raw == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return re, nil
+}
+
+// getEnvNamePolicy compiles a container name allowlist that must match
+// the whole name, so that "web" does not allow "webhook-admin"
+func getEnvNamePolicy(key string) (*regexp.Regexp, error) {
+	raw := getEnv(key, "")
+	if raw == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(`^(?:` + raw + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return re, nil
+}
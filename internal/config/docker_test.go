@@ -0,0 +1,35 @@
+// internal/config/docker_test.go
+package config
+
+import "testing"
+
+func TestDockerNamePoliciesMatchWholeNames(t *testing.T) {
+	t.Setenv("AGENT_DOCKER_EXEC_NAMES", "web|api-[0-9]+")
+	t.Setenv("AGENT_DOCKER_ACTIONS_NAMES", "web|api-[0-9]+")
+
+	cfg, err := loadDockerConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		allow bool
+	}{
+		{"web", true},
+		{"api-1", true},
+		{"webhook-admin", false},
+		{"prod-web-db", false},
+		{"api-1-old", false},
+		{"my-api-1", false},
+	}
+
+	for _, c := range cases {
+		if got := cfg.Exec.Names.MatchString(c.name); got != c.allow {
+			t.Errorf("exec %s: allowed = %v, want %v", c.name, got, c.allow)
+		}
+		if got := cfg.Actions.Names.MatchString(c.name); got != c.allow {
+			t.Errorf("actions on %s: allowed = %v, want %v", c.name, got, c.allow)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+)
+
+const (
+	RuntimeAuto       = "auto"
+	RuntimeDocker     = "docker"
+	RuntimePodman     = "podman"
+	RuntimeContainerd = "containerd"
+	RuntimeKubernetes = "kubernetes"
+	RuntimeNone       = "none"
+)
+
+// Mounted into every pod by Kubernetes
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+type RuntimeConfig struct {
+	// "auto" probes the known sockets at startup
+	Name string
+
+	// Podman API socket; probed in the usual rootful and rootless
+	// locations when empty
+	PodmanSocket string
+
+	// containerd gRPC socket; probed in the containerd and k3s
+	// locations when empty
+	ContainerdAddress string
+	// containerd namespaces to report; all when empty
+	ContainerdNamespaces []string
+
+	Kubelet KubeletConfig
+}
+
+// KubeletConfig is used in Kubernetes node mode, with the agent deployed
+// as a DaemonSet and reading its own node's kubelet
+type KubeletConfig struct {
+	URL       string // e.g. https://10.0.0.5:10250
+	NodeName  string
+	TokenFile string // service account token, re-read on every request
+	CAFile    string
+	// Kubelet serving certificates are often self-signed
+	InsecureSkipVerify bool
+}
+
+func loadRuntimeConfig() (RuntimeConfig, error) {
+	cfg := RuntimeConfig{
+		Name:                 getEnv("AGENT_CONTAINER_RUNTIME", RuntimeAuto),
+		PodmanSocket:         getEnv("AGENT_PODMAN_SOCKET", ""),
+		ContainerdAddress:    getEnv("AGENT_CONTAINERD_ADDRESS", ""),
+		ContainerdNamespaces: getEnvList("AGENT_CONTAINERD_NAMESPACES"),
+	}
+
+	cfg.Kubelet = KubeletConfig{
+		NodeName:           getEnv("NODE_NAME", ""),
+		TokenFile:          getEnv("AGENT_KUBELET_TOKEN_FILE", serviceAccountDir+"/token"),
+		CAFile:             getEnv("AGENT_KUBELET_CA_FILE", serviceAccountDir+"/ca.crt"),
+		InsecureSkipVerify: getEnvBool("AGENT_KUBELET_INSECURE_SKIP_VERIFY", false),
+	}
+	cfg.Kubelet.URL = getEnv("AGENT_KUBELET_URL", "")
+	if cfg.Kubelet.URL == "" && cfg.Kubelet.NodeName != "" {
+		cfg.Kubelet.URL = "https://" + cfg.Kubelet.NodeName + ":10250"
+	}
+
+	names := []string{RuntimeAuto, RuntimeDocker, RuntimePodman, RuntimeContainerd, RuntimeKubernetes, RuntimeNone}
+	if !slices.Contains(names, cfg.Name) {
+		return cfg, fmt.Errorf("invalid AGENT_CONTAINER_RUNTIME: must be one of %v", names)
+	}
+
+	return cfg, nil
+}
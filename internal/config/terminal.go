@@ -0,0 +1,225 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Terminal modes
+const (
+	TerminalShell      = "shell"      // interactive shells and exec sessions
+	TerminalRestricted = "restricted" // allowlisted commands only
+	TerminalDisabled   = "disabled"
+)
+
+// Read-only commands allowed in restricted mode unless
+// AGENT_TERMINAL_COMMANDS is set. Unit names may not start with a dash,
+// which would make them options such as journalctl --file.
+const defaultTerminalCommands = `uptime; df (-h)?; free (-[hm])?; ` +
+	`docker ps( -a)?; docker stats --no-stream; ` +
+	`systemctl status [A-Za-z0-9@._][A-Za-z0-9@._-]*; ` +
+	`journalctl -u [A-Za-z0-9@._][A-Za-z0-9@._-]*( -n [0-9]+)?`
+
+// TerminalConfig covers the remote terminal sessions the backend opens
+// over the agent WebSocket
+type TerminalConfig struct {
+	// shell, restricted or disabled. Restricted and disabled refuse
+	// terminal:open, for host shells and container exec alike.
+	Mode string
+
+	// Commands terminal:run may execute in shell and restricted mode
+	Commands []TerminalCommand
+	// Limits for a single terminal:run
+	CommandTimeout   time.Duration
+	CommandMaxOutput int // bytes
+
+	// Concurrent sessions per connection, host shells and container
+	// exec sessions together; also caps concurrent terminal:run commands
+	MaxSessions int
+
+	// Host shells run as User and Group, which need the agent to run as
+	// root; empty keeps the agent's user and that user's primary group
+	User  string
+	Group string
+	// Empty means the user's login shell, then bash, then sh
+	Shell string
+	// Empty means the user's home directory
+	WorkDir string
+	// Variables copied from the agent's environment into the shell, on
+	// top of HOME, USER, LOGNAME, SHELL, PATH and TERM
+	Env []string
+
+	// A session without input for IdleTimeout, or open for MaxDuration,
+	// is closed after a warning TimeoutWarning ahead; 0 disables either
+	IdleTimeout    time.Duration
+	MaxDuration    time.Duration
+	TimeoutWarning time.Duration
+
+	Recording TerminalRecordingConfig
+}
+
+// TerminalCommand allows running Name with arguments that, joined by
+// single spaces, match Args in full. A nil Args allows no arguments.
+type TerminalCommand struct {
+	Name string
+	Args *regexp.Regexp
+}
+
+// Allows reports whether the policy entry matches a requested command.
+// Arguments with whitespace never match, so that joining them cannot
+// fake a different argument list.
+func (c TerminalCommand) Allows(name string, args []string) bool {
+	if name != c.Name {
+		return false
+	}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsFunc(arg, unicode.IsSpace) {
+			return false
+		}
+	}
+	if c.Args == nil {
+		return len(args) == 0
+	}
+	return c.Args.MatchString(strings.Join(args, " "))
+}
+
+// TerminalRecordingConfig controls the asciicast recordings of terminal
+// sessions kept for auditing
+type TerminalRecordingConfig struct {
+	Enabled bool
+	// Empty means "recordings" in the agent data directory
+	Dir string
+	// Also record keystrokes, including anything typed at a password
+	// prompt
+	Input bool
+
+	// Retention, applied whenever a recording is finished; 0 disables
+	MaxAge    time.Duration
+	MaxSizeMB int
+
+	// Send finished recordings to the backend as well
+	Upload bool
+}
+
+func loadTerminalConfig() (TerminalConfig, error) {
+	var (
+		cfg TerminalConfig
+		err error
+	)
+
+	cfg.Mode = getEnv("AGENT_TERMINAL_MODE", TerminalShell)
+	if cfg.Mode != TerminalShell && cfg.Mode != TerminalRestricted && cfg.Mode != TerminalDisabled {
+		return cfg, fmt.Errorf("invalid AGENT_TERMINAL_MODE: must be %q, %q or %q", TerminalShell, TerminalRestricted, TerminalDisabled)
+	}
+
+	if cfg.Commands, err = parseTerminalCommands(getEnv("AGENT_TERMINAL_COMMANDS", defaultTerminalCommands)); err != nil {
+		return cfg, err
+	}
+	if cfg.CommandTimeout, err = getEnvDuration("AGENT_TERMINAL_COMMAND_TIMEOUT", 30*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.CommandMaxOutput, err = getEnvInt("AGENT_TERMINAL_COMMAND_MAX_OUTPUT", 1024*1024); err != nil {
+		return cfg, err
+	}
+	if cfg.CommandTimeout <= 0 || cfg.CommandMaxOutput < 1 {
+		return cfg, fmt.Errorf("AGENT_TERMINAL_COMMAND_TIMEOUT and AGENT_TERMINAL_COMMAND_MAX_OUTPUT must be positive")
+	}
+
+	if cfg.MaxSessions, err = getEnvInt("AGENT_TERMINAL_MAX_SESSIONS", 4); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxSessions < 1 {
+		return cfg, fmt.Errorf("invalid AGENT_TERMINAL_MAX_SESSIONS: must be at least 1")
+	}
+
+	cfg.User = getEnv("AGENT_TERMINAL_USER", "")
+	cfg.Group = getEnv("AGENT_TERMINAL_GROUP", "")
+	cfg.Shell = getEnv("AGENT_TERMINAL_SHELL", "")
+	cfg.WorkDir = getEnv("AGENT_TERMINAL_WORKDIR", "")
+
+	cfg.Env = getEnvList("AGENT_TERMINAL_ENV")
+	if cfg.Env == nil {
+		cfg.Env = []string{"LANG", "LC_ALL", "TZ"}
+	}
+	for _, name := range cfg.Env {
+		// The agent's own settings, the API key among them, never
+		// reach a shell
+		if strings.HasPrefix(name, "AGENT_") {
+			return cfg, fmt.Errorf("invalid AGENT_TERMINAL_ENV: %s may not be passed to shells", name)
+		}
+	}
+
+	if cfg.IdleTimeout, err = getEnvDuration("AGENT_TERMINAL_IDLE_TIMEOUT", 15*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxDuration, err = getEnvDuration("AGENT_TERMINAL_MAX_DURATION", 8*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.TimeoutWarning, err = getEnvDuration("AGENT_TERMINAL_TIMEOUT_WARNING", time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.IdleTimeout < 0 || cfg.MaxDuration < 0 || cfg.TimeoutWarning < 0 {
+		return cfg, fmt.Errorf("terminal timeouts must not be negative")
+	}
+
+	if cfg.Recording, err = loadTerminalRecordingConfig(); err != nil {
+		return cfg, err
+	}
+
+	return cfg, nil
+}
+
+// parseTerminalCommands reads "name [args regex]" entries separated by
+// semicolons, e.g. "uptime; journalctl -u [a-z0-9@._][a-z0-9@._-]*"
+func parseTerminalCommands(raw string) ([]TerminalCommand, error) {
+	var commands []TerminalCommand
+	for _, entry := range strings.Split(raw, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, args, _ := strings.Cut(entry, " ")
+		if strings.ContainsAny(name, "/\\") {
+			return nil, fmt.Errorf("invalid AGENT_TERMINAL_COMMANDS: %q must be a command name, not a path", name)
+		}
+
+		cmd := TerminalCommand{Name: name}
+		if args = strings.TrimSpace(args); args != "" {
+			re, err := regexp.Compile(`^(?:` + args + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("invalid AGENT_TERMINAL_COMMANDS entry %q: %w", entry, err)
+			}
+			cmd.Args = re
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+func loadTerminalRecordingConfig() (TerminalRecordingConfig, error) {
+	var (
+		cfg TerminalRecordingConfig
+		err error
+	)
+
+	cfg.Enabled = getEnvBool("AGENT_TERMINAL_RECORD", true)
+	cfg.Dir = getEnv("AGENT_TERMINAL_RECORD_DIR", "")
+	cfg.Input = getEnvBool("AGENT_TERMINAL_RECORD_INPUT", false)
+	cfg.Upload = getEnvBool("AGENT_TERMINAL_RECORD_UPLOAD", false)
+
+	if cfg.MaxAge, err = getEnvDuration("AGENT_TERMINAL_RECORD_MAX_AGE", 30*24*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxSizeMB, err = getEnvInt("AGENT_TERMINAL_RECORD_MAX_SIZE_MB", 500); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxAge < 0 || cfg.MaxSizeMB < 0 {
+		return cfg, fmt.Errorf("AGENT_TERMINAL_RECORD_MAX_AGE and AGENT_TERMINAL_RECORD_MAX_SIZE_MB must not be negative")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,110 @@
+// internal/config/terminal_test.go
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTerminalCommands(t *testing.T) {
+	cases := []struct {
+		raw     string
+		names   []string
+		wantErr bool
+	}{
+		{raw: "", names: nil},
+		{raw: "uptime", names: []string{"uptime"}},
+		{raw: " uptime ;; df (-h)? ; ", names: []string{"uptime", "df"}},
+		{raw: defaultTerminalCommands, names: []string{"uptime", "df", "free", "docker", "docker", "systemctl", "journalctl"}},
+		{raw: "/bin/sh -c .*", wantErr: true},
+		{raw: "bin/sh", wantErr: true},
+		{raw: `C:\sh`, wantErr: true},
+		{raw: "df (-h", wantErr: true},
+	}
+
+	for _, c := range cases {
+		commands, err := parseTerminalCommands(c.raw)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", c.raw)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", c.raw, err)
+			continue
+		}
+
+		var names []string
+		for _, cmd := range commands {
+			names = append(names, cmd.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(c.names, ",") {
+			t.Errorf("%q: got commands %v, want %v", c.raw, names, c.names)
+		}
+	}
+}
+
+func TestTerminalCommandAllows(t *testing.T) {
+	commands, err := parseTerminalCommands(defaultTerminalCommands)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name  string
+		args  []string
+		allow bool
+	}{
+		{"uptime", nil, true},
+		{"uptime", []string{"-p"}, false},
+		{"df", nil, true},
+		{"df", []string{"-h"}, true},
+		{"df", []string{"-hT"}, false},
+		{"free", []string{"-m"}, true},
+		{"docker", []string{"ps"}, true},
+		{"docker", []string{"ps", "-a"}, true},
+		{"docker", []string{"stats", "--no-stream"}, true},
+		{"docker", []string{"stats"}, false},
+		{"docker", []string{"rm", "-f", "web"}, false},
+		{"systemctl", []string{"status", "nginx.service"}, true},
+		{"systemctl", []string{"status", "getty@tty1"}, true},
+		{"journalctl", []string{"-u", "nginx", "-n", "50"}, true},
+
+		// Anchored at both ends
+		{"docker", []string{"ps", "-a", "-q"}, false},
+		{"docker", []string{"x", "ps"}, false},
+		{"journalctl", []string{"-u", "nginx", "-n", "50", "-f"}, false},
+
+		// Arguments may not join into an allowed line
+		{"docker", []string{"ps -a"}, false},
+		{"systemctl", []string{"status", "nginx\tsshd"}, false},
+		{"systemctl", []string{"status", "nginx\nsshd"}, false},
+		{"journalctl", []string{"-u", "nginx", "", "-n", "5"}, false},
+		{"df", []string{""}, false},
+
+		// A value may not be an option
+		{"systemctl", []string{"status", "--all"}, false},
+		{"systemctl", []string{"status", "-H"}, false},
+		{"journalctl", []string{"-u", "--file=/etc/shadow"}, false},
+		{"journalctl", []string{"-u", "-D"}, false},
+
+		// Only the bare name is matched
+		{"/usr/bin/uptime", nil, false},
+		{"./uptime", nil, false},
+		{"UPTIME", nil, false},
+	}
+
+	for _, c := range cases {
+		allowed := false
+		for _, cmd := range commands {
+			if cmd.Allows(c.name, c.args) {
+				allowed = true
+				break
+			}
+		}
+		if allowed != c.allow {
+			t.Errorf("%s %q: allowed = %v, want %v", c.name, c.args, allowed, c.allow)
+		}
+	}
+}
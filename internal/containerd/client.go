@@ -0,0 +1,97 @@
+// internal/containerd/client.go
+package containerd
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/pkg/logger"
+
+	client "github.com/containerd/containerd/v2/client"
+)
+
+const (
+	pingTimeout = 5 * time.Second
+	// Reconnect attempts while containerd is unreachable
+	retryInterval = 30 * time.Second
+)
+
+// Client reads containers and their cgroup stats from containerd over
+// its gRPC socket, for hosts without a Docker-compatible API (k3s,
+// nerdctl). Events, logs and container actions are Docker-only; the
+// AGENT_DOCKER_* filters and label allowlist apply here too.
+type Client struct {
+	cli        *client.Client
+	namespaces []string // all namespaces when empty
+	filter     config.DockerFilterConfig
+	labels     []string
+
+	// available tracks the last call; gRPC reconnects on its own
+	available atomic.Bool
+	lastRetry atomic.Int64 // unix nanoseconds
+
+	mu   sync.Mutex
+	prev map[string]sample
+}
+
+func NewClient(cfg *config.Config, address string) (*Client, error) {
+	cli, err := client.New(address, client.WithTimeout(pingTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		cli:        cli,
+		namespaces: cfg.Runtime.ContainerdNamespaces,
+		filter:     cfg.Docker.Filter,
+		labels:     cfg.Docker.Labels,
+		prev:       make(map[string]sample),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if _, err := cli.Version(ctx); err != nil {
+		logger.Warn("containerd not available at %s, will retry: %v", address, err)
+		c.lastRetry.Store(time.Now().UnixNano())
+	} else {
+		logger.Info("containerd client connected successfully (%s)", address)
+		c.available.Store(true)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Name() string {
+	return config.RuntimeContainerd
+}
+
+// IsAvailable pings containerd again, at most every retryInterval,
+// while it is unreachable
+func (c *Client) IsAvailable() bool {
+	if c.available.Load() {
+		return true
+	}
+
+	now := time.Now()
+	if now.Sub(time.Unix(0, c.lastRetry.Load())) < retryInterval {
+		return false
+	}
+	c.lastRetry.Store(now.UnixNano())
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+	if _, err := c.cli.Version(ctx); err != nil {
+		return false
+	}
+
+	logger.Info("containerd connected, container metrics resumed")
+	c.available.Store(true)
+	return true
+}
+
+func (c *Client) Close() error {
+	return c.cli.Close()
+}
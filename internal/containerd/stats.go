@@ -0,0 +1,329 @@
+// internal/containerd/stats.go
+package containerd
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"pulse_agent/internal/counter"
+	"pulse_agent/internal/docker"
+	"pulse_agent/internal/models"
+	"pulse_agent/internal/system"
+	"pulse_agent/pkg/logger"
+
+	cgroup1 "github.com/containerd/cgroups/v3/cgroup1/stats"
+	cgroup2 "github.com/containerd/cgroups/v3/cgroup2/stats"
+	client "github.com/containerd/containerd/v2/client"
+	"github.com/containerd/containerd/v2/pkg/namespaces"
+	"github.com/containerd/errdefs"
+	"github.com/containerd/typeurl/v2"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// Labels set by the CRI plugin (Kubernetes, k3s) and by nerdctl
+const (
+	labelKind          = "io.cri-containerd.kind"
+	labelPodName       = "io.kubernetes.pod.name"
+	labelContainerName = "io.kubernetes.container.name"
+	labelNerdctlName   = "nerdctl/name"
+)
+
+// sample holds the cumulative counters of the previous collection
+type sample struct {
+	at         time.Time
+	cpuNs      uint64
+	readBytes  uint64
+	writeBytes uint64
+}
+
+// counters is what both cgroup versions are reduced to
+type counters struct {
+	cpuNs        uint64
+	memUsage     uint64
+	memLimit     uint64
+	cache        uint64
+	rss          uint64
+	inactiveFile uint64
+	pids         uint64
+	pidsLimit    uint64
+	readBytes    uint64
+	writeBytes   uint64
+}
+
+func (c *Client) GetContainerStats(ctx context.Context) ([]models.ContainerMetric, error) {
+	nsList := c.namespaces
+	if len(nsList) == 0 {
+		var err error
+		if nsList, err = c.cli.NamespaceService().List(ctx); err != nil {
+			c.available.Store(false)
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var metrics []models.ContainerMetric
+	for _, ns := range nsList {
+		nsCtx := namespaces.WithNamespace(ctx, ns)
+
+		containers, err := c.cli.Containers(nsCtx)
+		if err != nil {
+			c.available.Store(false)
+			return nil, fmt.Errorf("list containers in %s: %w", ns, err)
+		}
+
+		for _, ctr := range containers {
+			metric, ok := c.containerMetric(nsCtx, ns, ctr)
+			if !ok {
+				continue
+			}
+			seen[ctr.ID()] = true
+			// containerd cannot list by state
+			if !docker.StateWanted(c.filter, metric.State) {
+				continue
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+
+	c.prune(seen)
+	return metrics, nil
+}
+
+// containerMetric returns false for pod sandboxes, filtered containers
+// and containers that disappeared while listing
+func (c *Client) containerMetric(ctx context.Context, ns string, ctr client.Container) (models.ContainerMetric, bool) {
+	info, err := ctr.Info(ctx, client.WithoutRefreshedMetadata)
+	if err != nil {
+		return models.ContainerMetric{}, false
+	}
+	// Kubernetes pause containers
+	if info.Labels[labelKind] == "sandbox" {
+		return models.ContainerMetric{}, false
+	}
+
+	metric := models.ContainerMetric{
+		ID:        docker.ShortID(info.ID),
+		Name:      containerName(ns, info.ID, info.Labels),
+		Image:     info.Image,
+		Labels:    docker.SelectLabels(info.Labels, c.labels),
+		State:     "created",
+		CreatedAt: info.CreatedAt,
+	}
+	if !docker.MatchesFilter(c.filter, metric.Name, metric.Image, info.Labels) {
+		return models.ContainerMetric{}, false
+	}
+
+	task, err := ctr.Task(ctx, nil)
+	if err != nil {
+		if !errdefs.IsNotFound(err) {
+			logger.Debug("Failed to load task of %s: %v", metric.Name, err)
+		}
+		return metric, true
+	}
+
+	status, err := task.Status(ctx)
+	if err != nil {
+		return metric, true
+	}
+
+	switch status.Status {
+	case client.Stopped:
+		metric.State = "exited"
+		metric.ExitCode = int(status.ExitStatus)
+		metric.Status = fmt.Sprintf("Exited (%d)", status.ExitStatus)
+		return metric, true
+	case client.Running:
+		metric.State = "running"
+		metric.Status = "Up"
+	default:
+		metric.State = string(status.Status)
+		metric.Status = string(status.Status)
+	}
+
+	if status.Status != client.Running {
+		return metric, true
+	}
+
+	if err := c.applyMetrics(ctx, task, info.ID, &metric); err != nil {
+		logger.Warn("Failed to get stats for %s: %v", metric.Name, err)
+		metric.StatsError = err.Error()
+	}
+	return metric, true
+}
+
+func (c *Client) applyMetrics(ctx context.Context, task client.Task, id string, metric *models.ContainerMetric) error {
+	m, err := task.Metrics(ctx)
+	if err != nil {
+		return err
+	}
+
+	data, err := typeurl.UnmarshalAny(m.Data)
+	if err != nil {
+		return err
+	}
+
+	var cnt counters
+	switch stats := data.(type) {
+	case *cgroup2.Metrics:
+		cnt = fromCgroup2(stats)
+	case *cgroup1.Metrics:
+		cnt = fromCgroup1(stats)
+	default:
+		return fmt.Errorf("unexpected metrics type %T", data)
+	}
+
+	metric.MemoryUsageMB = int(cnt.memUsage / 1024 / 1024)
+	metric.MemoryLimitMB = int(cnt.memLimit / 1024 / 1024)
+	metric.MemoryCacheMB = int(cnt.cache / 1024 / 1024)
+	metric.MemoryRSSMB = int(cnt.rss / 1024 / 1024)
+	if cnt.inactiveFile < cnt.memUsage {
+		metric.MemoryWorkingSetMB = int((cnt.memUsage - cnt.inactiveFile) / 1024 / 1024)
+	}
+	metric.PIDs = cnt.pids
+	metric.PIDsLimit = cnt.pidsLimit
+
+	c.applyRates(id, metric, cnt, time.Now())
+
+	// containerd has no network stats; read them from the task's
+	// network namespace
+	if pid := task.Pid(); pid > 0 {
+		c.applyNetwork(ctx, pid, metric)
+	}
+
+	return nil
+}
+
+func fromCgroup2(stats *cgroup2.Metrics) counters {
+	var cnt counters
+	if cpu := stats.CPU; cpu != nil {
+		cnt.cpuNs = cpu.UsageUsec * 1000
+	}
+	if mem := stats.Memory; mem != nil {
+		cnt.memUsage = mem.Usage
+		cnt.memLimit = mem.UsageLimit
+		cnt.cache = mem.File
+		cnt.rss = mem.Anon
+		cnt.inactiveFile = mem.InactiveFile
+	}
+	if pids := stats.Pids; pids != nil {
+		cnt.pids = pids.Current
+		cnt.pidsLimit = pids.Limit
+	}
+	if io := stats.Io; io != nil {
+		for _, entry := range io.Usage {
+			cnt.readBytes += entry.Rbytes
+			cnt.writeBytes += entry.Wbytes
+		}
+	}
+	return cnt.unlimited()
+}
+
+func fromCgroup1(stats *cgroup1.Metrics) counters {
+	var cnt counters
+	if cpu := stats.CPU; cpu != nil && cpu.Usage != nil {
+		cnt.cpuNs = cpu.Usage.Total
+	}
+	if mem := stats.Memory; mem != nil {
+		if mem.Usage != nil {
+			cnt.memUsage = mem.Usage.Usage
+			cnt.memLimit = mem.Usage.Limit
+		}
+		cnt.cache = mem.TotalCache
+		cnt.rss = mem.TotalRSS
+		cnt.inactiveFile = mem.TotalInactiveFile
+	}
+	if pids := stats.Pids; pids != nil {
+		cnt.pids = pids.Current
+		cnt.pidsLimit = pids.Limit
+	}
+	if blkio := stats.Blkio; blkio != nil {
+		for _, entry := range blkio.IoServiceBytesRecursive {
+			switch entry.Op {
+			case "Read", "read":
+				cnt.readBytes += entry.Value
+			case "Write", "write":
+				cnt.writeBytes += entry.Value
+			}
+		}
+	}
+	return cnt.unlimited()
+}
+
+// unlimited reports "no limit" as 0 rather than as a huge number
+func (cnt counters) unlimited() counters {
+	// cgroup v1 reports the page-aligned max int64 when unlimited
+	if cnt.memLimit >= math.MaxInt64/2 {
+		cnt.memLimit = 0
+	}
+	if cnt.pidsLimit >= math.MaxInt64/2 {
+		cnt.pidsLimit = 0
+	}
+	return cnt
+}
+
+// applyRates computes CPU percent (relative to one core, as the docker
+// CLI does) and block I/O rates against the previous collection
+func (c *Client) applyRates(id string, metric *models.ContainerMetric, cnt counters, now time.Time) {
+	c.mu.Lock()
+	prev, ok := c.prev[id]
+	c.prev[id] = sample{at: now, cpuNs: cnt.cpuNs, readBytes: cnt.readBytes, writeBytes: cnt.writeBytes}
+	c.mu.Unlock()
+
+	elapsed := now.Sub(prev.at)
+	if !ok || elapsed <= 0 {
+		return
+	}
+
+	seconds := elapsed.Seconds()
+	if cnt.cpuNs >= prev.cpuNs {
+		metric.CPUPercent = float64(cnt.cpuNs-prev.cpuNs) / float64(elapsed.Nanoseconds()) * 100.0
+	}
+	metric.BlockReadBytesPerSec = counter.Rate(prev.readBytes, cnt.readBytes, seconds)
+	metric.BlockWriteBytesPerSec = counter.Rate(prev.writeBytes, cnt.writeBytes, seconds)
+}
+
+func (c *Client) applyNetwork(ctx context.Context, pid uint32, metric *models.ContainerMetric) {
+	counters, err := net.IOCountersByFileWithContext(ctx, true, system.HostProc(strconv.FormatUint(uint64(pid), 10), "net", "dev"))
+	if err != nil {
+		// Host network or the agent cannot see the host /proc
+		return
+	}
+
+	var rxBytes, txBytes uint64
+	for _, nic := range counters {
+		if nic.Name == "lo" {
+			continue
+		}
+		rxBytes += nic.BytesRecv
+		txBytes += nic.BytesSent
+	}
+
+	metric.NetworkRxMB = float64(rxBytes) / 1024 / 1024
+	metric.NetworkTxMB = float64(txBytes) / 1024 / 1024
+}
+
+// prune forgets containers that no longer exist
+func (c *Client) prune(seen map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range c.prev {
+		if !seen[id] {
+			delete(c.prev, id)
+		}
+	}
+}
+
+// containerName prefers the Kubernetes pod/container or nerdctl name
+func containerName(ns, id string, labels map[string]string) string {
+	if pod, name := labels[labelPodName], labels[labelContainerName]; pod != "" && name != "" {
+		return pod + "/" + name
+	}
+	if name := labels[labelNerdctlName]; name != "" {
+		return name
+	}
+	return ns + "/" + docker.ShortID(id)
+}
@@ -0,0 +1,11 @@
+// internal/counter/counter.go
+package counter
+
+// Rate returns the per-second increase of a cumulative counter, or 0
+// when the counter was reset
+func Rate[T ~int64 | ~uint64](prev, curr T, elapsedSeconds float64) float64 {
+	if elapsedSeconds <= 0 || curr < prev {
+		return 0
+	}
+	return float64(curr-prev) / elapsedSeconds
+}
@@ -0,0 +1,110 @@
+// internal/docker/actions.go
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"pulse_agent/internal/config"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// ErrActionDenied is returned when the local policy does not allow an action
+var ErrActionDenied = errors.New("not allowed by agent policy")
+
+// ContainerRef identifies the container an action was applied to
+type ContainerRef struct {
+	ID   string
+	Name string
+}
+
+// RunAction resolves a container by name or ID and applies a lifecycle
+// action to it, if the local policy in config.DockerActionsConfig allows it
+func (c *Client) RunAction(ctx context.Context, action, ref string) (ContainerRef, error) {
+	if !slices.Contains(config.DockerActions, action) {
+		return ContainerRef{}, fmt.Errorf("unknown action %q", action)
+	}
+	if !slices.Contains(c.cfg.Actions.Allowed, action) {
+		return ContainerRef{}, fmt.Errorf("%s: %w", action, ErrActionDenied)
+	}
+	if !c.IsAvailable() {
+		return ContainerRef{}, errors.New("docker not available")
+	}
+
+	ctr, err := c.findContainer(ctx, ref)
+	if err != nil {
+		return ContainerRef{}, err
+	}
+
+	target := ContainerRef{ID: ShortID(ctr.ID), Name: containerName(ctr)}
+	if !actionTargetAllowed(c.cfg.Actions, ctr) {
+		return target, fmt.Errorf("%s on %s: %w", action, target.Name, ErrActionDenied)
+	}
+
+	timeout := int(c.cfg.Actions.StopTimeout.Seconds())
+	switch action {
+	case "start":
+		err = c.cli.ContainerStart(ctx, ctr.ID, container.StartOptions{})
+	case "stop":
+		err = c.cli.ContainerStop(ctx, ctr.ID, container.StopOptions{Timeout: &timeout})
+	case "restart":
+		err = c.cli.ContainerRestart(ctx, ctr.ID, container.StopOptions{Timeout: &timeout})
+	case "pause":
+		err = c.cli.ContainerPause(ctx, ctr.ID)
+	case "unpause":
+		err = c.cli.ContainerUnpause(ctx, ctr.ID)
+	case "remove":
+		// Not forced: a running container has to be stopped first
+		err = c.cli.ContainerRemove(ctx, ctr.ID, container.RemoveOptions{})
+	}
+
+	// State and restart count changed
+	c.inspects.invalidate(ctr.ID)
+	return target, err
+}
+
+// findContainer matches a name, a full ID or an unambiguous ID prefix
+func (c *Client) findContainer(ctx context.Context, ref string) (container.Summary, error) {
+	ref = strings.TrimPrefix(ref, "/")
+	if ref == "" {
+		return container.Summary{}, errors.New("no container given")
+	}
+
+	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	if err != nil {
+		return container.Summary{}, err
+	}
+
+	var matches []container.Summary
+	for _, ctr := range containers {
+		if ctr.ID == ref || containerName(ctr) == ref {
+			return ctr, nil
+		}
+		if strings.HasPrefix(ctr.ID, ref) {
+			matches = append(matches, ctr)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return container.Summary{}, fmt.Errorf("no such container: %s", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return container.Summary{}, fmt.Errorf("container ID prefix %s is ambiguous", ref)
+	}
+}
+
+func actionTargetAllowed(cfg config.DockerActionsConfig, ctr container.Summary) bool {
+	if cfg.Names == nil && len(cfg.Labels) == 0 {
+		return true
+	}
+	if cfg.Names != nil && cfg.Names.MatchString(containerName(ctr)) {
+		return true
+	}
+	return hasAnyLabel(ctr.Labels, cfg.Labels)
+}
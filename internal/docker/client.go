@@ -3,40 +3,82 @@ package docker
 
 import (
 	"context"
-	"pulse_agent/pkg/logger"
+	"sync/atomic"
+
+	"pulse_agent/internal/config"
 
 	"github.com/docker/docker/client"
 )
 
 type Client struct {
-	cli *client.Client
+	// "docker", or "podman" when talking to Podman's compatible API
+	name string
+
+	// ctx bounds background watchers; cancelled by Close
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	cli      *client.Client
+	cfg      config.DockerConfig
+	streams  *streamManager // nil in poll mode
+	rates    *rateTracker
+	inspects *inspectCache
+
+	// available tracks the last ping; the daemon may come and go
+	available atomic.Bool
+	recheck   chan struct{}
+	reported  bool // initial state logged, owned by the connection watcher
+
+	diskUsage diskUsageState
+}
+
+// NewClient only fails on invalid client settings. A daemon that is not
+// reachable yet is retried in the background, see IsAvailable.
+func NewClient(cfg *config.Config) (*Client, error) {
+	return newClient(cfg, config.RuntimeDocker, client.FromEnv)
 }
 
-func NewClient() (*Client, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+// NewPodmanClient talks to Podman through its Docker-compatible API
+func NewPodmanClient(cfg *config.Config, socket string) (*Client, error) {
+	return newClient(cfg, config.RuntimePodman, client.WithHost("unix://"+socket))
+}
+
+func newClient(cfg *config.Config, name string, host client.Opt) (*Client, error) {
+	cli, err := client.NewClientWithOpts(host, client.WithAPIVersionNegotiation())
 	if err != nil {
 		return nil, err
 	}
 
-	// Test connection
-	ctx := context.Background()
-	_, err = cli.Ping(ctx)
-	if err != nil {
-		logger.Warn("Docker not available: %v", err)
-		return nil, err
+	c := &Client{
+		name:     name,
+		cli:      cli,
+		cfg:      cfg.Docker,
+		rates:    newRateTracker(),
+		inspects: newInspectCache(),
+		recheck:  make(chan struct{}, 1),
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	if cfg.Docker.StatsMode == config.DockerStatsStream {
+		c.streams = newStreamManager(cli)
 	}
 
-	logger.Info("Docker client connected successfully")
-	return &Client{cli: cli}, nil
+	// First ping inline so the first payload reports the right state
+	go c.watchConnection(c.ping())
+	return c, nil
 }
 
 func (c *Client) Close() error {
-	if c.cli != nil {
-		return c.cli.Close()
+	c.cancel()
+	if c.streams != nil {
+		c.streams.closeAll()
 	}
-	return nil
+	return c.cli.Close()
+}
+
+func (c *Client) Name() string {
+	return c.name
 }
 
 func (c *Client) IsAvailable() bool {
-	return c.cli != nil
+	return c.available.Load()
 }
@@ -0,0 +1,74 @@
+// internal/docker/compose.go
+package docker
+
+import (
+	"sort"
+
+	"pulse_agent/internal/models"
+)
+
+const (
+	composeProjectLabel = "com.docker.compose.project"
+	composeServiceLabel = "com.docker.compose.service"
+)
+
+// GroupByCompose aggregates containers per Compose project and service.
+// Containers not started by Compose are left out.
+func GroupByCompose(containers []models.ContainerMetric) []models.ComposeProjectMetric {
+	projects := make(map[string]*models.ComposeProjectMetric)
+	services := make(map[string]map[string]*models.ComposeServiceMetric)
+
+	for _, ctr := range containers {
+		if ctr.ComposeProject == "" {
+			continue
+		}
+
+		project, ok := projects[ctr.ComposeProject]
+		if !ok {
+			project = &models.ComposeProjectMetric{Project: ctr.ComposeProject}
+			projects[ctr.ComposeProject] = project
+			services[ctr.ComposeProject] = make(map[string]*models.ComposeServiceMetric)
+		}
+
+		service, ok := services[ctr.ComposeProject][ctr.ComposeService]
+		if !ok {
+			service = &models.ComposeServiceMetric{Service: ctr.ComposeService}
+			services[ctr.ComposeProject][ctr.ComposeService] = service
+		}
+
+		running := ctr.State == "running"
+		unhealthy := ctr.Health == "unhealthy"
+
+		project.ContainerCount++
+		service.ContainerCount++
+		if running {
+			project.RunningCount++
+			service.RunningCount++
+		}
+		if unhealthy {
+			project.UnhealthyCount++
+			service.UnhealthyCount++
+		}
+		project.CPUPercent += ctr.CPUPercent
+		service.CPUPercent += ctr.CPUPercent
+		project.MemoryUsageMB += ctr.MemoryUsageMB
+		service.MemoryUsageMB += ctr.MemoryUsageMB
+	}
+
+	result := make([]models.ComposeProjectMetric, 0, len(projects))
+	for name, project := range projects {
+		for _, service := range services[name] {
+			project.Services = append(project.Services, *service)
+		}
+		sort.Slice(project.Services, func(i, j int) bool {
+			return project.Services[i].Service < project.Services[j].Service
+		})
+		result = append(result, *project)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Project < result[j].Project
+	})
+
+	return result
+}
@@ -0,0 +1,107 @@
+// internal/docker/connection.go
+package docker
+
+import (
+	"context"
+	"time"
+
+	"pulse_agent/pkg/logger"
+)
+
+const (
+	connectRetryMin = 1 * time.Second
+	connectRetryMax = 60 * time.Second
+	// How often a connected daemon is pinged to notice it went away
+	connectCheckInterval = 15 * time.Second
+	pingTimeout          = 5 * time.Second
+)
+
+// watchConnection keeps pinging the daemon until the client is closed,
+// retrying with backoff while it is unreachable. err is the result of
+// the initial ping.
+func (c *Client) watchConnection(err error) {
+	backoff := connectRetryMin
+
+	for {
+		wait := connectCheckInterval
+		if err != nil {
+			wait = backoff
+			backoff = min(backoff*2, connectRetryMax)
+		} else {
+			backoff = connectRetryMin
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-c.recheck:
+		case <-time.After(wait):
+		}
+		err = c.ping()
+	}
+}
+
+// ping updates the availability flag and logs state changes only
+func (c *Client) ping() error {
+	ctx, cancel := context.WithTimeout(c.ctx, pingTimeout)
+	defer cancel()
+
+	_, err := c.cli.Ping(ctx)
+	if err != nil {
+		if c.ctx.Err() != nil {
+			return err
+		}
+		if c.available.Swap(false) {
+			logger.Warn("Lost connection to Docker daemon: %v", err)
+			c.resetState()
+		} else if !c.reported {
+			logger.Warn("Docker not available, retrying in background: %v", err)
+			c.reported = true
+		} else {
+			logger.Debug("Docker still not available: %v", err)
+		}
+		return err
+	}
+
+	if !c.available.Swap(true) {
+		if c.reported {
+			logger.Info("Docker daemon connected, container metrics resumed")
+		} else {
+			logger.Info("Docker client connected successfully")
+		}
+		c.reported = true
+	}
+	return nil
+}
+
+// checkConnection asks the watcher to ping right away, e.g. when a
+// long-lived stream from the daemon broke
+func (c *Client) checkConnection() {
+	select {
+	case c.recheck <- struct{}{}:
+	default:
+	}
+}
+
+// resetState drops per-daemon state that is invalid after a restart.
+// Stats streams and log tails end on their own and are restarted by the
+// next sync, cached inspect data would otherwise linger for its TTL.
+func (c *Client) resetState() {
+	if c.streams != nil {
+		c.streams.closeAll()
+	}
+	c.inspects.clear()
+}
+
+// waitAvailable blocks until the daemon is reachable; it returns false
+// when the client was closed first
+func (c *Client) waitAvailable() bool {
+	for !c.IsAvailable() {
+		select {
+		case <-c.ctx.Done():
+			return false
+		case <-time.After(connectRetryMin):
+		}
+	}
+	return c.ctx.Err() == nil
+}
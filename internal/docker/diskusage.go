@@ -0,0 +1,168 @@
+// internal/docker/diskusage.go
+package docker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+
+	"github.com/docker/docker/api/types"
+)
+
+// system df walks every layer and volume and can take minutes on busy hosts
+const diskUsageTimeout = 2 * time.Minute
+
+type diskUsageState struct {
+	mu     sync.Mutex
+	latest *models.DockerDiskMetric
+}
+
+// CollectDiskUsage refreshes the Docker disk usage summary in the
+// background every interval until the client is closed, pausing while
+// the daemon is unreachable
+func (c *Client) CollectDiskUsage(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			// Refreshed right away once a missing daemon comes up
+			if !c.waitAvailable() {
+				return
+			}
+			c.refreshDiskUsage()
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// TakeDiskUsage returns the latest summary once, or nil if there was
+// no refresh since the previous call. A summary that fails to send is
+// handed back with RequeueDiskUsage.
+func (c *Client) TakeDiskUsage() *models.DockerDiskMetric {
+	c.diskUsage.mu.Lock()
+	defer c.diskUsage.mu.Unlock()
+
+	latest := c.diskUsage.latest
+	c.diskUsage.latest = nil
+	return latest
+}
+
+// RequeueDiskUsage puts back a summary taken by TakeDiskUsage that could
+// not be sent; a newer refresh wins
+func (c *Client) RequeueDiskUsage(metric *models.DockerDiskMetric) {
+	c.diskUsage.mu.Lock()
+	defer c.diskUsage.mu.Unlock()
+
+	if c.diskUsage.latest == nil {
+		c.diskUsage.latest = metric
+	}
+}
+
+func (c *Client) refreshDiskUsage() {
+	ctx, cancel := context.WithTimeout(c.ctx, diskUsageTimeout)
+	defer cancel()
+
+	start := time.Now()
+	du, err := c.cli.DiskUsage(ctx, types.DiskUsageOptions{})
+	if err != nil {
+		if c.ctx.Err() == nil {
+			logger.Warn("Failed to collect Docker disk usage: %v", err)
+		}
+		return
+	}
+
+	metric := diskUsageMetric(du)
+	logger.Debug("Docker disk usage collected in %v", time.Since(start))
+
+	c.diskUsage.mu.Lock()
+	c.diskUsage.latest = metric
+	c.diskUsage.mu.Unlock()
+}
+
+func diskUsageMetric(du types.DiskUsage) *models.DockerDiskMetric {
+	metric := &models.DockerDiskMetric{
+		CollectedAt:  time.Now(),
+		ImageCount:   len(du.Images),
+		ImagesSizeMB: toMB(du.LayersSize),
+		Volumes:      make([]models.DockerVolumeMetric, 0, len(du.Volumes)),
+		Containers:   make([]models.ContainerDiskUsageMetric, 0, len(du.Containers)),
+	}
+
+	// Same accounting as "docker system df": layers not used by any
+	// container are reclaimable
+	var imagesInUse int64
+	for _, img := range du.Images {
+		if img.Containers > 0 && img.SharedSize != -1 {
+			imagesInUse += img.Size - img.SharedSize
+		}
+		if isDangling(img.RepoTags) {
+			metric.DanglingImageCount++
+			metric.DanglingImagesMB += toMB(img.Size)
+		}
+	}
+	metric.ImagesReclaimableMB = toMB(du.LayersSize - imagesInUse)
+
+	for _, ctr := range du.Containers {
+		size := toMB(ctr.SizeRw)
+		metric.ContainersSizeMB += size
+		if ctr.State != "running" {
+			metric.ContainersReclaimableMB += size
+		}
+		metric.Containers = append(metric.Containers, models.ContainerDiskUsageMetric{
+			ID:              ShortID(ctr.ID),
+			Name:            containerName(*ctr),
+			State:           ctr.State,
+			WritableLayerMB: size,
+		})
+	}
+
+	for _, vol := range du.Volumes {
+		v := models.DockerVolumeMetric{Name: vol.Name, Driver: vol.Driver, SizeMB: -1}
+		if vol.UsageData != nil {
+			v.RefCount = vol.UsageData.RefCount
+			if vol.UsageData.Size >= 0 {
+				v.SizeMB = toMB(vol.UsageData.Size)
+				metric.VolumesSizeMB += v.SizeMB
+				if v.RefCount == 0 {
+					metric.VolumesReclaimableMB += v.SizeMB
+				}
+			}
+		}
+		metric.Volumes = append(metric.Volumes, v)
+	}
+	metric.VolumeCount = len(metric.Volumes)
+
+	for _, record := range du.BuildCache {
+		if record.Shared {
+			continue
+		}
+		size := toMB(record.Size)
+		metric.BuildCacheSizeMB += size
+		if !record.InUse {
+			metric.BuildCacheReclaimableMB += size
+		}
+	}
+
+	return metric
+}
+
+func isDangling(repoTags []string) bool {
+	return len(repoTags) == 0 || (len(repoTags) == 1 && repoTags[0] == "<none>:<none>")
+}
+
+func toMB(bytes int64) float64 {
+	return float64(bytes) / 1024 / 1024
+}
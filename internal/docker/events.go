@@ -0,0 +1,161 @@
+// internal/docker/events.go
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pulse_agent/internal/events"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+
+	dockerevents "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+const (
+	eventsRetryMin = 1 * time.Second
+	eventsRetryMax = 30 * time.Second
+)
+
+// WatchEvents forwards container lifecycle and image pull events into
+// buf until the client is closed. The subscription is re-established
+// with backoff, resuming from the last event seen, and paused while
+// the daemon is unreachable.
+func (c *Client) WatchEvents(buf *events.Buffer) {
+	go func() {
+		var since time.Time
+		backoff := eventsRetryMin
+
+		for {
+			if !c.waitAvailable() {
+				return
+			}
+
+			last := since
+			err := c.streamEvents(buf, &since)
+			if c.ctx.Err() != nil {
+				return
+			}
+
+			// The stream was healthy for a while, start over with a short delay
+			if since.After(last) {
+				backoff = eventsRetryMin
+			}
+			logger.Warn("Docker events stream interrupted: %v (retrying in %v)", err, backoff)
+			// Usually the daemon went away or restarted
+			c.checkConnection()
+
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, eventsRetryMax)
+		}
+	}()
+}
+
+func (c *Client) streamEvents(buf *events.Buffer, since *time.Time) error {
+	args := filters.NewArgs(
+		filters.Arg("type", string(dockerevents.ContainerEventType)),
+		filters.Arg("type", string(dockerevents.ImageEventType)),
+	)
+	for _, action := range []dockerevents.Action{
+		dockerevents.ActionStart,
+		dockerevents.ActionDie,
+		dockerevents.ActionOOM,
+		dockerevents.ActionKill,
+		dockerevents.ActionRestart,
+		dockerevents.ActionHealthStatus,
+		dockerevents.ActionDestroy,
+		dockerevents.ActionPull,
+	} {
+		args.Add("event", string(action))
+	}
+
+	opts := dockerevents.ListOptions{Filters: args}
+	if !since.IsZero() {
+		opts.Since = strconv.FormatInt(since.Unix(), 10)
+	}
+
+	messages, errs := c.cli.Events(c.ctx, opts)
+	for {
+		select {
+		case msg := <-messages:
+			ts := time.Unix(0, msg.TimeNano)
+			// Since has second granularity, skip events replayed on resume
+			if !ts.After(*since) {
+				continue
+			}
+			*since = ts
+
+			if msg.Type == dockerevents.ContainerEventType {
+				// State, health or restart count changed
+				c.inspects.invalidate(msg.Actor.ID)
+
+				if msg.Action == dockerevents.ActionStart && c.streams != nil {
+					// Subscribe right away so the next cycle has a sample
+					c.streams.ensure(msg.Actor.ID)
+				}
+			}
+
+			buf.Push(toEvent(msg, ts))
+		case err := <-errs:
+			return err
+		}
+	}
+}
+
+func toEvent(msg dockerevents.Message, ts time.Time) models.Event {
+	attrs := msg.Actor.Attributes
+	action, detail, _ := strings.Cut(string(msg.Action), ":")
+	detail = strings.TrimSpace(detail)
+
+	event := models.Event{
+		Type:       fmt.Sprintf("%s_%s", msg.Type, action),
+		Source:     "docker",
+		Attributes: map[string]string{},
+		Timestamp:  ts,
+	}
+
+	if msg.Type == dockerevents.ImageEventType {
+		event.Subject = msg.Actor.ID
+		event.Message = fmt.Sprintf("Image %s pulled", msg.Actor.ID)
+		return event
+	}
+
+	name := attrs["name"]
+	event.Subject = name
+	event.Attributes["container_id"] = ShortID(msg.Actor.ID)
+	event.Attributes["image"] = attrs["image"]
+
+	switch dockerevents.Action(action) {
+	case dockerevents.ActionDie:
+		event.Attributes["exit_code"] = attrs["exitCode"]
+		event.Message = fmt.Sprintf("Container %s exited with code %s", name, attrs["exitCode"])
+	case dockerevents.ActionKill:
+		event.Attributes["signal"] = attrs["signal"]
+		event.Message = fmt.Sprintf("Container %s killed with signal %s", name, attrs["signal"])
+	case dockerevents.ActionOOM:
+		event.Message = fmt.Sprintf("Container %s ran out of memory", name)
+	case dockerevents.ActionHealthStatus:
+		event.Attributes["health_status"] = detail
+		event.Message = fmt.Sprintf("Container %s is %s", name, detail)
+	default:
+		event.Message = fmt.Sprintf("Container %s: %s", name, action)
+	}
+
+	return event
+}
+
+// ShortID is the 12 character container ID the Docker CLI shows; the
+// other runtimes use it too
+func ShortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
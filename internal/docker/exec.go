@@ -0,0 +1,219 @@
+// internal/docker/exec.go
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/terminal"
+	"pulse_agent/pkg/logger"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+const (
+	// Between the hangup on Close and killing the exec process
+	execKillDelay = 5 * time.Second
+	// How long Wait waits for the daemon to report the exit code
+	execExitTimeout  = execKillDelay + 5*time.Second
+	execPollInterval = 100 * time.Millisecond
+)
+
+// Picks bash when the image has it; most minimal images only ship sh
+var defaultExecCmd = []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash; else exec sh; fi"}
+
+// ExecSession is an interactive TTY inside a container, started with
+// docker exec. It implements terminal.Terminal.
+type ExecSession struct {
+	cli         *client.Client
+	id          string
+	containerID string
+	conn        types.HijackedResponse
+
+	closeOnce sync.Once
+}
+
+// Exec resolves a container by name or ID and starts an interactive shell
+// in it, if the local policy in config.DockerExecConfig allows it. The
+// container must be running.
+func (c *Client) Exec(ctx context.Context, ref string, rows, cols uint16) (*ExecSession, ContainerRef, error) {
+	if !c.cfg.Exec.Enabled() {
+		return nil, ContainerRef{}, fmt.Errorf("exec: %w", ErrActionDenied)
+	}
+	if !c.IsAvailable() {
+		return nil, ContainerRef{}, errors.New("docker not available")
+	}
+
+	ctr, err := c.findContainer(ctx, ref)
+	if err != nil {
+		return nil, ContainerRef{}, err
+	}
+
+	target := ContainerRef{ID: ShortID(ctr.ID), Name: containerName(ctr)}
+	if !execTargetAllowed(c.cfg.Exec, ctr) {
+		return nil, target, fmt.Errorf("exec in %s: %w", target.Name, ErrActionDenied)
+	}
+	if ctr.State != container.StateRunning {
+		return nil, target, fmt.Errorf("container %s is %s", target.Name, ctr.State)
+	}
+
+	cmd := defaultExecCmd
+	if c.cfg.Exec.Shell != "" {
+		cmd = []string{c.cfg.Exec.Shell}
+	}
+
+	var size *[2]uint
+	if rows > 0 && cols > 0 {
+		size = &[2]uint{uint(rows), uint(cols)}
+	}
+
+	created, err := c.cli.ContainerExecCreate(ctx, ctr.ID, container.ExecOptions{
+		User:         c.cfg.Exec.User,
+		Tty:          true,
+		ConsoleSize:  size,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm-256color"},
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return nil, target, err
+	}
+
+	// Attaching also starts the exec
+	conn, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: size,
+	})
+	if err != nil {
+		return nil, target, err
+	}
+
+	return &ExecSession{cli: c.cli, id: created.ID, containerID: ctr.ID, conn: conn}, target, nil
+}
+
+func (s *ExecSession) Write(data []byte) error {
+	_, err := s.conn.Conn.Write(data)
+	return err
+}
+
+// ReadLoop relays the raw TTY output; with a TTY Docker does not
+// multiplex stdout and stderr
+func (s *ExecSession) ReadLoop(fn func([]byte)) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := s.conn.Reader.Read(buf)
+		if n > 0 {
+			fn(buf[:n])
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *ExecSession) Resize(rows, cols uint16) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.cli.ContainerExecResize(ctx, s.id, container.ResizeOptions{
+		Height: uint(rows),
+		Width:  uint(cols),
+	})
+}
+
+// Wait polls the daemon until the exec process is gone and returns its
+// exit code; the daemon may lag behind the end of the output stream. An
+// exec still running after execExitTimeout reports -1.
+func (s *ExecSession) Wait() terminal.ExitStatus {
+	deadline := time.Now().Add(execExitTimeout)
+	for {
+		inspect, err := s.inspect()
+		if err == nil && !inspect.Running {
+			return terminal.ExitStatus{Code: inspect.ExitCode}
+		}
+		if time.Now().After(deadline) {
+			return terminal.ExitStatus{Code: -1}
+		}
+		time.Sleep(execPollInterval)
+	}
+}
+
+// Close detaches and ends the exec process. Docker leaves it running
+// when the attach stream goes away, so it is hung up like Session.Close
+// does, then killed if still there after execKillDelay.
+func (s *ExecSession) Close() {
+	s.closeOnce.Do(func() {
+		s.conn.Close()
+
+		if !s.signal(syscall.SIGHUP) {
+			return
+		}
+		go func() {
+			deadline := time.Now().Add(execKillDelay)
+			for time.Now().Before(deadline) {
+				if inspect, err := s.inspect(); err == nil && !inspect.Running {
+					return
+				}
+				time.Sleep(execPollInterval)
+			}
+			s.signal(syscall.SIGKILL)
+		}()
+	})
+}
+
+// signal sends sig to the exec's process group if it is still running,
+// and reports whether it was sent. The daemon reports the host PID, which
+// the agent can only signal when it shares the host PID namespace; the
+// PID is checked to belong to the container so that a PID from another
+// namespace never hits an unrelated process.
+func (s *ExecSession) signal(sig syscall.Signal) bool {
+	inspect, err := s.inspect()
+	if err != nil || !inspect.Running || inspect.Pid <= 0 {
+		return false
+	}
+
+	if !pidInContainer(inspect.Pid, s.containerID) {
+		logger.Warn("Cannot stop exec %s: PID %d is not visible to the agent (needs the host PID namespace)", ShortID(s.id), inspect.Pid)
+		return false
+	}
+
+	// With a TTY the exec leads its own session and process group
+	if err := syscall.Kill(-inspect.Pid, sig); err != nil {
+		if err := syscall.Kill(inspect.Pid, sig); err != nil {
+			logger.Warn("Failed to signal exec %s: %v", ShortID(s.id), err)
+			return false
+		}
+	}
+	return true
+}
+
+func (s *ExecSession) inspect() (container.ExecInspect, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.cli.ContainerExecInspect(ctx, s.id)
+}
+
+// pidInContainer checks the process's cgroup, which names the container
+// ID for Docker and Podman on cgroup v1 and v2
+func pidInContainer(pid int, containerID string) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	return err == nil && strings.Contains(string(data), containerID)
+}
+
+func execTargetAllowed(cfg config.DockerExecConfig, ctr container.Summary) bool {
+	if cfg.Names != nil && cfg.Names.MatchString(containerName(ctr)) {
+		return true
+	}
+	return hasAnyLabel(ctr.Labels, cfg.Labels)
+}
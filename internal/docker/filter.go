@@ -0,0 +1,128 @@
+// internal/docker/filter.go
+package docker
+
+import (
+	"strings"
+
+	"pulse_agent/internal/config"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+)
+
+// listOptions pushes the state filters down to the daemon so that
+// stopped containers are not even listed when they are not wanted
+func listOptions(cfg config.DockerFilterConfig) container.ListOptions {
+	opts := container.ListOptions{All: !cfg.SkipStopped}
+
+	// Of the listed states only running survives SkipStopped; config
+	// makes sure it is listed
+	states := cfg.States
+	if cfg.SkipStopped && len(states) > 0 {
+		states = []string{container.StateRunning}
+	}
+
+	if len(states) > 0 {
+		opts.All = true
+		opts.Filters = filters.NewArgs()
+		for _, state := range states {
+			opts.Filters.Add("status", state)
+		}
+	}
+
+	return opts
+}
+
+func containerMatches(cfg config.DockerFilterConfig, ctr container.Summary) bool {
+	return MatchesFilter(cfg, containerName(ctr), ctr.Image, ctr.Labels)
+}
+
+// MatchesFilter applies the name, image and label filters; other
+// runtimes that report containers use it too
+func MatchesFilter(cfg config.DockerFilterConfig, name, image string, labels map[string]string) bool {
+	if cfg.IncludeNames != nil && !cfg.IncludeNames.MatchString(name) {
+		return false
+	}
+	if cfg.ExcludeNames != nil && cfg.ExcludeNames.MatchString(name) {
+		return false
+	}
+
+	if len(cfg.IncludeImages) > 0 && !hasAnyPrefix(image, cfg.IncludeImages) {
+		return false
+	}
+	if hasAnyPrefix(image, cfg.ExcludeImages) {
+		return false
+	}
+
+	if len(cfg.IncludeLabels) > 0 && !hasAnyLabel(labels, cfg.IncludeLabels) {
+		return false
+	}
+	if hasAnyLabel(labels, cfg.ExcludeLabels) {
+		return false
+	}
+
+	return true
+}
+
+// StateWanted applies the state filters to runtimes that cannot filter
+// when listing, as the Docker API does with listOptions
+func StateWanted(cfg config.DockerFilterConfig, state string) bool {
+	if cfg.SkipStopped && state != container.StateRunning {
+		return false
+	}
+	if len(cfg.States) == 0 {
+		return true
+	}
+	for _, s := range cfg.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectLabels copies the allowlisted labels that are set on a container
+func SelectLabels(labels map[string]string, allowlist []string) map[string]string {
+	if len(allowlist) == 0 {
+		return nil
+	}
+
+	selected := make(map[string]string)
+	for _, key := range allowlist {
+		if value, ok := labels[key]; ok {
+			selected[key] = value
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil
+	}
+	return selected
+}
+
+func containerName(ctr container.Summary) string {
+	if len(ctr.Names) == 0 {
+		return ShortID(ctr.ID)
+	}
+	return strings.TrimPrefix(ctr.Names[0], "/")
+}
+
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyLabel matches selectors of the form "key=value" or "key"
+func hasAnyLabel(labels map[string]string, selectors []string) bool {
+	for _, selector := range selectors {
+		key, value, hasValue := strings.Cut(selector, "=")
+		if v, ok := labels[key]; ok && (!hasValue || v == value) {
+			return true
+		}
+	}
+	return false
+}
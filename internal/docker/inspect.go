@@ -0,0 +1,145 @@
+// internal/docker/inspect.go
+package docker
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+const (
+	// Entries are refreshed on container events; the TTL covers
+	// periods where the events stream is down
+	inspectTTL = 5 * time.Minute
+
+	maxHealthOutput = 1024
+)
+
+type inspectInfo struct {
+	fetched time.Time
+
+	health              string
+	healthFailingStreak int
+	lastHealthOutput    string
+	restartCount        int
+	restartPolicy       string
+	oomKilled           bool
+	exitCode            int
+	cpuLimitCores       float64
+}
+
+// inspectCache avoids a ContainerInspect call per container per cycle
+type inspectCache struct {
+	mu      sync.Mutex
+	entries map[string]*inspectInfo
+}
+
+func newInspectCache() *inspectCache {
+	return &inspectCache{entries: make(map[string]*inspectInfo)}
+}
+
+func (c *Client) inspect(ctx context.Context, containerID string) (*inspectInfo, error) {
+	c.inspects.mu.Lock()
+	info, ok := c.inspects.entries[containerID]
+	c.inspects.mu.Unlock()
+	if ok && time.Since(info.fetched) < inspectTTL {
+		return info, nil
+	}
+
+	resp, err := c.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	info = &inspectInfo{
+		fetched:      time.Now(),
+		restartCount: resp.RestartCount,
+	}
+
+	if resp.State != nil {
+		info.oomKilled = resp.State.OOMKilled
+		info.exitCode = resp.State.ExitCode
+
+		if h := resp.State.Health; h != nil {
+			info.health = h.Status
+			info.healthFailingStreak = h.FailingStreak
+			if len(h.Log) > 0 {
+				output := strings.TrimSpace(h.Log[len(h.Log)-1].Output)
+				if len(output) > maxHealthOutput {
+					output = output[:maxHealthOutput]
+				}
+				info.lastHealthOutput = output
+			}
+		}
+	}
+
+	if resp.HostConfig != nil {
+		info.restartPolicy = string(resp.HostConfig.RestartPolicy.Name)
+		info.cpuLimitCores = cpuLimitCores(resp.HostConfig.Resources)
+	}
+
+	c.inspects.mu.Lock()
+	c.inspects.entries[containerID] = info
+	c.inspects.mu.Unlock()
+
+	return info, nil
+}
+
+// invalidate forces a fresh inspect on the next collection
+func (ic *inspectCache) invalidate(containerID string) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	delete(ic.entries, containerID)
+}
+
+// clear drops every entry, used after a daemon restart
+func (ic *inspectCache) clear() {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	ic.entries = make(map[string]*inspectInfo)
+}
+
+// prune drops entries for containers that no longer exist
+func (ic *inspectCache) prune(existing map[string]bool) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	for id := range ic.entries {
+		if !existing[id] {
+			delete(ic.entries, id)
+		}
+	}
+}
+
+func applyInspect(metric *models.ContainerMetric, info *inspectInfo) {
+	metric.Health = info.health
+	metric.HealthFailingStreak = info.healthFailingStreak
+	metric.LastHealthOutput = info.lastHealthOutput
+	metric.RestartCount = info.restartCount
+	metric.RestartPolicy = info.restartPolicy
+	metric.OOMKilled = info.oomKilled
+	metric.ExitCode = info.exitCode
+	metric.CPULimitCores = info.cpuLimitCores
+}
+
+// cpuLimitCores returns the CPU limit in cores, from --cpus or from
+// --cpu-quota/--cpu-period, or 0 when the container is unlimited
+func cpuLimitCores(res container.Resources) float64 {
+	if res.NanoCPUs > 0 {
+		return float64(res.NanoCPUs) / 1e9
+	}
+	if res.CPUQuota > 0 {
+		period := res.CPUPeriod
+		if period <= 0 {
+			// Kernel default CFS period, 100ms
+			period = 100000
+		}
+		return float64(res.CPUQuota) / float64(period)
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+// internal/docker/logoffsets.go
+package docker
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/agent"
+)
+
+// Offsets of containers that logged nothing for this long are forgotten
+const logOffsetRetention = 7 * 24 * time.Hour
+
+// logOffsets remembers the timestamp of the last shipped line per
+// container so forwarding resumes where it left off after a restart.
+type logOffsets struct {
+	mu      sync.Mutex
+	path    string
+	offsets map[string]time.Time
+}
+
+func loadLogOffsets() *logOffsets {
+	o := &logOffsets{
+		path:    filepath.Join(agent.DataDir(), "log_offsets.json"),
+		offsets: make(map[string]time.Time),
+	}
+
+	if data, err := os.ReadFile(o.path); err == nil {
+		_ = json.Unmarshal(data, &o.offsets)
+	}
+	return o
+}
+
+func (o *logOffsets) get(containerID string) (time.Time, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	ts, ok := o.offsets[containerID]
+	return ts, ok
+}
+
+func (o *logOffsets) set(containerID string, ts time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if ts.After(o.offsets[containerID]) {
+		o.offsets[containerID] = ts
+	}
+}
+
+func (o *logOffsets) save() error {
+	o.mu.Lock()
+	for id, ts := range o.offsets {
+		if time.Since(ts) > logOffsetRetention {
+			delete(o.offsets, id)
+		}
+	}
+	data, err := json.Marshal(o.offsets)
+	o.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	_ = os.MkdirAll(filepath.Dir(o.path), 0700)
+	return os.WriteFile(o.path, data, 0600)
+}
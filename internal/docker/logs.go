@@ -0,0 +1,391 @@
+// internal/docker/logs.go
+package docker
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"golang.org/x/time/rate"
+)
+
+const (
+	logsReconcileInterval = 10 * time.Second
+	// A multiline record is flushed after this long without a continuation
+	logsMultilineWait = 500 * time.Millisecond
+	// Failed batches are retried, up to this many batches worth of lines
+	logsMaxPendingBatches = 10
+	logsMaxRetryDelay     = 5 * time.Minute
+	// Bounds the last flush on shutdown
+	LogsFinalFlushTimeout = 5 * time.Second
+)
+
+// LogShipper delivers a batch of log lines to the backend
+type LogShipper func(ctx context.Context, entries []models.LogEntry, dropped int) error
+
+// LogForwarder follows the logs of selected containers and ships them
+// in batches, resuming from the last shipped timestamp after a restart.
+type LogForwarder struct {
+	client  *Client
+	cfg     config.DockerLogsConfig
+	ship    LogShipper
+	limiter *rate.Limiter
+	offsets *logOffsets
+	started time.Time
+	entries chan models.LogEntry
+	done    chan struct{}
+
+	mu      sync.Mutex
+	tails   map[string]context.CancelFunc
+	dropped int
+}
+
+// ForwardLogs starts log forwarding; it stops when the client is closed,
+// after a last flush that Done reports
+func (c *Client) ForwardLogs(cfg config.DockerLogsConfig, ship LogShipper) *LogForwarder {
+	f := &LogForwarder{
+		client:  c,
+		cfg:     cfg,
+		ship:    ship,
+		limiter: rate.NewLimiter(rate.Limit(cfg.RateLimit), cfg.RateLimit),
+		offsets: loadLogOffsets(),
+		started: time.Now(),
+		entries: make(chan models.LogEntry, cfg.BatchSize),
+		done:    make(chan struct{}),
+		tails:   make(map[string]context.CancelFunc),
+	}
+
+	go f.reconcileLoop()
+	go f.batchLoop()
+
+	logger.Info("Container log forwarding enabled")
+	return f
+}
+
+func (f *LogForwarder) reconcileLoop() {
+	ticker := time.NewTicker(logsReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		// Tails end with the daemon and are restarted once it is back
+		if f.client.IsAvailable() {
+			if err := f.reconcile(); err != nil && f.client.ctx.Err() == nil {
+				logger.Warn("Failed to list containers for log forwarding: %v", err)
+			}
+		}
+
+		select {
+		case <-f.client.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// reconcile follows newly matching containers and stops following
+// containers that are gone
+func (f *LogForwarder) reconcile() error {
+	containers, err := f.client.cli.ContainerList(f.client.ctx, container.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wanted := make(map[string]bool)
+	for _, ctr := range containers {
+		if !f.selected(ctr) {
+			continue
+		}
+		wanted[ctr.ID] = true
+
+		if _, ok := f.tails[ctr.ID]; !ok {
+			ctx, cancel := context.WithCancel(f.client.ctx)
+			f.tails[ctr.ID] = cancel
+			go f.tail(ctx, ctr)
+		}
+	}
+
+	for id, cancel := range f.tails {
+		if !wanted[id] {
+			cancel()
+			delete(f.tails, id)
+		}
+	}
+
+	return nil
+}
+
+func (f *LogForwarder) selected(ctr container.Summary) bool {
+	if f.cfg.Names != nil && f.cfg.Names.MatchString(containerName(ctr)) {
+		return true
+	}
+	if hasAnyPrefix(ctr.Image, f.cfg.Images) {
+		return true
+	}
+	return f.cfg.Label != "" && hasAnyLabel(ctr.Labels, []string{f.cfg.Label})
+}
+
+func (f *LogForwarder) tail(ctx context.Context, ctr container.Summary) {
+	defer func() {
+		f.mu.Lock()
+		if _, ok := f.tails[ctr.ID]; ok && ctx.Err() == nil {
+			// Stream ended on its own; the next reconcile restarts it
+			delete(f.tails, ctr.ID)
+		}
+		f.mu.Unlock()
+	}()
+
+	name := containerName(ctr)
+
+	info, err := f.client.cli.ContainerInspect(ctx, ctr.ID)
+	if err != nil {
+		logger.Warn("Failed to inspect %s for log forwarding: %v", name, err)
+		return
+	}
+
+	// Resume after the last shipped line, or start from agent startup
+	since, resumed := f.offsets.get(ctr.ID)
+	if !resumed {
+		since = f.started
+	}
+
+	rc, err := f.client.cli.ContainerLogs(ctx, ctr.ID, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     true,
+		Timestamps: true,
+		Since:      fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()),
+	})
+	if err != nil {
+		logger.Warn("Failed to follow logs of %s: %v", name, err)
+		return
+	}
+	defer rc.Close()
+
+	logger.Debug("Following logs of %s", name)
+
+	base := models.LogEntry{
+		ContainerID:   ctr.ID,
+		ContainerName: name,
+		Image:         ctr.Image,
+	}
+
+	// TTY containers have a single raw stream, others are multiplexed
+	if info.Config != nil && info.Config.Tty {
+		f.readLines(ctx, rc, base, "stdout", since)
+		return
+	}
+
+	stdoutR, stdoutW := io.Pipe()
+	stderrR, stderrW := io.Pipe()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); f.readLines(ctx, stdoutR, base, "stdout", since) }()
+	go func() { defer wg.Done(); f.readLines(ctx, stderrR, base, "stderr", since) }()
+
+	_, err = stdcopy.StdCopy(stdoutW, stderrW, rc)
+	stdoutW.CloseWithError(err)
+	stderrW.CloseWithError(err)
+	wg.Wait()
+}
+
+// readLines parses timestamped lines and joins multiline records
+func (f *LogForwarder) readLines(ctx context.Context, r io.Reader, base models.LogEntry, stream string, after time.Time) {
+	lines := make(chan models.LogEntry)
+
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line = strings.TrimRight(line, "\r\n"); line != "" {
+				entry := base
+				entry.Stream = stream
+				entry.Message = line
+				entry.Timestamp = time.Now()
+				if ts, msg, ok := strings.Cut(line, " "); ok {
+					if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+						entry.Timestamp, entry.Message = t, msg
+					}
+				}
+				// "since" has limited precision; skip lines already shipped
+				if entry.Timestamp.After(after) {
+					select {
+					case lines <- entry:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var pending *models.LogEntry
+	flush := func() {
+		if pending != nil {
+			f.emit(ctx, *pending)
+			pending = nil
+		}
+	}
+
+	timer := time.NewTimer(logsMultilineWait)
+	defer timer.Stop()
+
+	for {
+		select {
+		case entry, ok := <-lines:
+			if !ok {
+				flush()
+				return
+			}
+			if pending != nil && f.cfg.Multiline.MatchString(entry.Message) {
+				pending.Message += "\n" + entry.Message
+			} else {
+				flush()
+				pending = &entry
+			}
+			timer.Reset(logsMultilineWait)
+		case <-timer.C:
+			flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *LogForwarder) emit(ctx context.Context, entry models.LogEntry) {
+	if !f.limiter.Allow() {
+		f.mu.Lock()
+		f.dropped++
+		f.mu.Unlock()
+		return
+	}
+
+	select {
+	case f.entries <- entry:
+	case <-ctx.Done():
+	}
+}
+
+// batchLoop ships a batch once it is full or at every tick. After a
+// failure the batch is only retried on the ticker, backing off up to
+// logsMaxRetryDelay, while new lines queue up behind it.
+func (f *LogForwarder) batchLoop() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(f.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	var (
+		batch      []models.LogEntry
+		retryDelay time.Duration // zero unless the last flush failed
+		retryAt    time.Time
+	)
+	for {
+		select {
+		case entry := <-f.entries:
+			batch = f.trim(append(batch, entry))
+			if retryDelay > 0 || len(batch) < f.cfg.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if retryDelay > 0 && time.Now().Before(retryAt) {
+				continue
+			}
+		case <-f.client.ctx.Done():
+			// One last attempt, so lines read before a shutdown are not lost
+			ctx, cancel := context.WithTimeout(context.Background(), LogsFinalFlushTimeout)
+			f.flush(ctx, f.drain(batch))
+			cancel()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(f.client.ctx, 15*time.Second)
+		batch = f.flush(ctx, batch)
+		cancel()
+
+		if len(batch) == 0 {
+			retryDelay = 0
+			continue
+		}
+		retryDelay = min(max(2*retryDelay, f.cfg.FlushInterval), logsMaxRetryDelay)
+		retryAt = time.Now().Add(retryDelay)
+	}
+}
+
+// drain adds the lines still queued in entries
+func (f *LogForwarder) drain(batch []models.LogEntry) []models.LogEntry {
+	for {
+		select {
+		case entry := <-f.entries:
+			batch = f.trim(append(batch, entry))
+		default:
+			return batch
+		}
+	}
+}
+
+// Done is closed once forwarding stopped and the last batch was shipped
+// or given up on
+func (f *LogForwarder) Done() <-chan struct{} {
+	return f.done
+}
+
+// trim drops the oldest lines beyond logsMaxPendingBatches batches,
+// counting them as dropped
+func (f *LogForwarder) trim(batch []models.LogEntry) []models.LogEntry {
+	over := len(batch) - logsMaxPendingBatches*f.cfg.BatchSize
+	if over <= 0 {
+		return batch
+	}
+	f.mu.Lock()
+	f.dropped += over
+	f.mu.Unlock()
+	return batch[over:]
+}
+
+// flush ships the batch and returns what is left to retry
+func (f *LogForwarder) flush(ctx context.Context, batch []models.LogEntry) []models.LogEntry {
+	f.mu.Lock()
+	dropped := f.dropped
+	f.dropped = 0
+	f.mu.Unlock()
+
+	if len(batch) == 0 && dropped == 0 {
+		return batch
+	}
+
+	if err := f.ship(ctx, batch, dropped); err != nil {
+		logger.Warn("Failed to ship %d log lines: %v", len(batch), err)
+
+		f.mu.Lock()
+		f.dropped += dropped
+		f.mu.Unlock()
+		return batch
+	}
+
+	for _, entry := range batch {
+		f.offsets.set(entry.ContainerID, entry.Timestamp)
+	}
+	if err := f.offsets.save(); err != nil {
+		logger.Warn("Failed to save log offsets: %v", err)
+	}
+
+	return batch[:0]
+}
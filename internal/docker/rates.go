@@ -0,0 +1,121 @@
+// internal/docker/rates.go
+package docker
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/counter"
+	"pulse_agent/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// counterSample holds the cumulative counters of one stats sample
+type counterSample struct {
+	at               time.Time
+	blockRead        uint64
+	blockWrite       uint64
+	throttledPeriods uint64
+	throttledTime    uint64
+	networks         map[string]container.NetworkStats
+}
+
+// rateTracker turns cumulative container counters into per-second
+// rates between consecutive samples of the same container.
+type rateTracker struct {
+	mu   sync.Mutex
+	last map[string]counterSample
+}
+
+func newRateTracker() *rateTracker {
+	return &rateTracker{last: make(map[string]counterSample)}
+}
+
+func (t *rateTracker) apply(containerID string, metric *models.ContainerMetric, stats *container.StatsResponse) {
+	curr := counterSample{
+		at:               stats.Read,
+		throttledPeriods: stats.CPUStats.ThrottlingData.ThrottledPeriods,
+		throttledTime:    stats.CPUStats.ThrottlingData.ThrottledTime,
+		networks:         stats.Networks,
+	}
+	if curr.at.IsZero() {
+		curr.at = time.Now()
+	}
+
+	// cgroup v1 reports "Read"/"Write", cgroup v2 "read"/"write"
+	for _, entry := range stats.BlkioStats.IoServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			curr.blockRead += entry.Value
+		case "write":
+			curr.blockWrite += entry.Value
+		}
+	}
+
+	t.mu.Lock()
+	prev, ok := t.last[containerID]
+	t.last[containerID] = curr
+	t.mu.Unlock()
+
+	// Network list is reported even on the first sample
+	names := make([]string, 0, len(curr.networks))
+	for name := range curr.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metric.Networks = make([]models.ContainerNetworkMetric, 0, len(names))
+	for _, name := range names {
+		metric.Networks = append(metric.Networks, models.ContainerNetworkMetric{Name: name})
+	}
+
+	if !ok {
+		return
+	}
+	elapsed := curr.at.Sub(prev.at).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	metric.BlockReadBytesPerSec = counter.Rate(prev.blockRead, curr.blockRead, elapsed)
+	metric.BlockWriteBytesPerSec = counter.Rate(prev.blockWrite, curr.blockWrite, elapsed)
+	metric.CPUThrottledPeriodsPerSec = counter.Rate(prev.throttledPeriods, curr.throttledPeriods, elapsed)
+	// throttled_time is in nanoseconds
+	metric.CPUThrottledMsPerSec = counter.Rate(prev.throttledTime, curr.throttledTime, elapsed) / 1e6
+
+	for i := range metric.Networks {
+		n := &metric.Networks[i]
+		before, seen := prev.networks[n.Name]
+		if !seen {
+			continue
+		}
+		now := curr.networks[n.Name]
+
+		n.RxBytesPerSec = counter.Rate(before.RxBytes, now.RxBytes, elapsed)
+		n.TxBytesPerSec = counter.Rate(before.TxBytes, now.TxBytes, elapsed)
+		n.RxPacketsPerSec = counter.Rate(before.RxPackets, now.RxPackets, elapsed)
+		n.TxPacketsPerSec = counter.Rate(before.TxPackets, now.TxPackets, elapsed)
+		n.RxErrorsPerSec = counter.Rate(before.RxErrors, now.RxErrors, elapsed)
+		n.TxErrorsPerSec = counter.Rate(before.TxErrors, now.TxErrors, elapsed)
+		n.RxDroppedPerSec = counter.Rate(before.RxDropped, now.RxDropped, elapsed)
+		n.TxDroppedPerSec = counter.Rate(before.TxDropped, now.TxDropped, elapsed)
+
+		metric.NetworkRxBytesPerSec += n.RxBytesPerSec
+		metric.NetworkTxBytesPerSec += n.TxBytesPerSec
+	}
+}
+
+// prune forgets containers that are no longer running
+func (t *rateTracker) prune(running map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id := range t.last {
+		if !running[id] {
+			delete(t.last, id)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"runtime"
+	"sync"
 	"time"
 
 	"pulse_agent/internal/models"
@@ -14,48 +16,116 @@ import (
 )
 
 func (c *Client) GetContainerStats(ctx context.Context) ([]models.ContainerMetric, error) {
-	if c.cli == nil {
+	if !c.IsAvailable() {
 		return []models.ContainerMetric{}, nil
 	}
 
-	containers, err := c.cli.ContainerList(ctx, container.ListOptions{All: true})
+	listed, err := c.cli.ContainerList(ctx, listOptions(c.cfg.Filter))
 	if err != nil {
 		return nil, err
 	}
 
-	metrics := make([]models.ContainerMetric, 0, len(containers))
+	containers := make([]container.Summary, 0, len(listed))
+	for _, ctr := range listed {
+		if containerMatches(c.cfg.Filter, ctr) {
+			containers = append(containers, ctr)
+		}
+	}
 
+	existing := make(map[string]bool, len(containers))
+	running := make(map[string]bool, len(containers))
 	for _, ctr := range containers {
-		metric := models.ContainerMetric{
-			ID:        ctr.ID[:12],
-			Name:      ctr.Names[0][1:],
+		existing[ctr.ID] = true
+		if ctr.State == "running" {
+			running[ctr.ID] = true
+		}
+	}
+	c.inspects.prune(existing)
+	c.rates.prune(running)
+	if c.streams != nil {
+		c.streams.sync(running)
+	}
+
+	// Inspects past their TTL and each non-streaming stats call are daemon
+	// round trips, so containers are filled in by a bounded pool of
+	// workers. Results are written by index to keep ContainerList order.
+	metrics := make([]models.ContainerMetric, len(containers))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < c.cfg.StatsWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				c.fillContainer(ctx, containers[i], &metrics[i])
+			}
+		}()
+	}
+
+	for i, ctr := range containers {
+		metrics[i] = models.ContainerMetric{
+			ID:        ShortID(ctr.ID),
+			Name:      containerName(ctr),
 			Image:     ctr.Image,
 			State:     ctr.State,
 			Status:    ctr.Status,
 			CreatedAt: time.Unix(ctr.Created, 0),
+			Labels:    SelectLabels(ctr.Labels, c.cfg.Labels),
+
+			ComposeProject: ctr.Labels[composeProjectLabel],
+			ComposeService: ctr.Labels[composeServiceLabel],
 		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
 
-		if ctr.State == "running" {
-			stats, err := c.getSingleContainerStats(ctx, ctr.ID)
-			if err != nil {
-				logger.Warn("Failed to get stats for %s: %v", metric.Name, err)
-				continue
-			}
+	return metrics, nil
+}
+
+// fillContainer adds what inspect knows and, for a running container,
+// its latest streamed sample or freshly fetched stats
+func (c *Client) fillContainer(ctx context.Context, ctr container.Summary, metric *models.ContainerMetric) {
+	// Health, restart count and exit code are only known from inspect
+	if info, err := c.inspect(ctx, ctr.ID); err != nil {
+		logger.Warn("Failed to inspect %s: %v", metric.Name, err)
+	} else {
+		applyInspect(metric, info)
+	}
 
-			metric.CPUPercent = stats.CPUPercent
-			metric.MemoryUsageMB = stats.MemoryUsageMB
-			metric.MemoryLimitMB = stats.MemoryLimitMB
-			metric.NetworkRxMB = stats.NetworkRxMB
-			metric.NetworkTxMB = stats.NetworkTxMB
+	if ctr.State != "running" {
+		return
+	}
+
+	if c.streams != nil {
+		if stats, ok := c.streams.latest(ctr.ID); ok {
+			c.applyStats(ctr.ID, metric, stats)
+			return
 		}
+	}
+
+	c.fillContainerStats(ctx, ctr.ID, metric)
+}
+
+// fillContainerStats fetches stats for one container. On failure or
+// timeout the metric is kept with StatsError set, so that one slow
+// container does not hide the others.
+func (c *Client) fillContainerStats(ctx context.Context, containerID string, metric *models.ContainerMetric) {
+	statsCtx, cancel := context.WithTimeout(ctx, c.cfg.StatsTimeout)
+	defer cancel()
 
-		metrics = append(metrics, metric)
+	stats, err := c.getSingleContainerStats(statsCtx, containerID)
+	if err != nil {
+		logger.Warn("Failed to get stats for %s: %v", metric.Name, err)
+		metric.StatsError = err.Error()
+		return
 	}
 
-	return metrics, nil
+	c.applyStats(containerID, metric, stats)
 }
 
-func (c *Client) getSingleContainerStats(ctx context.Context, containerID string) (*models.ContainerMetric, error) {
+func (c *Client) getSingleContainerStats(ctx context.Context, containerID string) (*container.StatsResponse, error) {
 	resp, err := c.cli.ContainerStats(ctx, containerID, false)
 	if err != nil {
 		return nil, err
@@ -67,30 +137,42 @@ func (c *Client) getSingleContainerStats(ctx context.Context, containerID string
 		return nil, err
 	}
 
-	metric := &models.ContainerMetric{}
+	return &stats, nil
+}
 
+func (c *Client) applyStats(containerID string, metric *models.ContainerMetric, stats *container.StatsResponse) {
 	// CPU %
-	cpuDelta := float64(
-		stats.CPUStats.CPUUsage.TotalUsage -
-			stats.PreCPUStats.CPUUsage.TotalUsage,
-	)
-
-	systemDelta := float64(
-		stats.CPUStats.SystemUsage -
-			stats.PreCPUStats.SystemUsage,
-	)
-
-	if systemDelta > 0 && cpuDelta > 0 {
-		metric.CPUPercent =
-			(cpuDelta / systemDelta) *
-				float64(len(stats.CPUStats.CPUUsage.PercpuUsage)) * 100.0
+	metric.CPUPercent = cpuPercent(stats)
+	if metric.CPULimitCores > 0 {
+		metric.CPUQuotaPercent = metric.CPUPercent / metric.CPULimitCores
 	}
 
 	// Memory
 	metric.MemoryUsageMB = int(stats.MemoryStats.Usage / 1024 / 1024)
 	metric.MemoryLimitMB = int(stats.MemoryStats.Limit / 1024 / 1024)
 
-	// Network
+	// cgroup v1 and v2 name the page cache and anonymous memory differently
+	memStats := stats.MemoryStats.Stats
+	cache := firstStat(memStats, "file", "total_cache", "cache")
+	rss := firstStat(memStats, "anon", "total_rss", "rss")
+	inactiveFile := firstStat(memStats, "inactive_file", "total_inactive_file")
+
+	workingSet := stats.MemoryStats.Usage
+	if inactiveFile < workingSet {
+		workingSet -= inactiveFile
+	} else {
+		workingSet = 0
+	}
+
+	metric.MemoryCacheMB = int(cache / 1024 / 1024)
+	metric.MemoryRSSMB = int(rss / 1024 / 1024)
+	metric.MemoryWorkingSetMB = int(workingSet / 1024 / 1024)
+
+	// PIDs
+	metric.PIDs = stats.PidsStats.Current
+	metric.PIDsLimit = stats.PidsStats.Limit
+
+	// Network (cumulative totals)
 	var rxBytes, txBytes uint64
 	for _, net := range stats.Networks {
 		rxBytes += net.RxBytes
@@ -100,5 +182,45 @@ func (c *Client) getSingleContainerStats(ctx context.Context, containerID string
 	metric.NetworkRxMB = float64(rxBytes) / 1024 / 1024
 	metric.NetworkTxMB = float64(txBytes) / 1024 / 1024
 
-	return metric, nil
+	// Block I/O, throttling and per-network rates
+	c.rates.apply(containerID, metric, stats)
+}
+
+// cpuPercent uses the docker CLI formula: usage relative to one core,
+// so a container saturating two cores reports 200
+func cpuPercent(stats *container.StatsResponse) float64 {
+	// The first streamed sample has no previous reading
+	if stats.PreCPUStats.SystemUsage == 0 {
+		return 0
+	}
+
+	cpuDelta := float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+	systemDelta := float64(stats.CPUStats.SystemUsage) - float64(stats.PreCPUStats.SystemUsage)
+	if systemDelta <= 0 || cpuDelta <= 0 {
+		return 0
+	}
+
+	return cpuDelta / systemDelta * float64(onlineCPUs(stats)) * 100.0
+}
+
+// onlineCPUs prefers the count reported by the daemon. PercpuUsage is
+// only filled on cgroup v1 and old daemons report neither.
+func onlineCPUs(stats *container.StatsResponse) int {
+	if n := stats.CPUStats.OnlineCPUs; n > 0 {
+		return int(n)
+	}
+	if n := len(stats.CPUStats.CPUUsage.PercpuUsage); n > 0 {
+		return n
+	}
+	return runtime.NumCPU()
+}
+
+// firstStat returns the first key present in a cgroup memory stats map
+func firstStat(stats map[string]uint64, keys ...string) uint64 {
+	for _, key := range keys {
+		if value, ok := stats[key]; ok {
+			return value
+		}
+	}
+	return 0
 }
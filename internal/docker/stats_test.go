@@ -0,0 +1,165 @@
+// internal/docker/stats_test.go
+package docker
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"pulse_agent/internal/models"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// Fixtures are hand-written one-shot stats responses in the shape the
+// daemon returns on cgroup v1 (2 CPUs) and cgroup v2 (4 CPUs) hosts,
+// with round numbers so the expected values can be worked out by hand.
+// They are not captures: responses recorded from real v1 and v2 daemons
+// (GET /containers/{id}/stats?stream=false) should replace them, along with
+// the expected values below.
+func loadStats(t *testing.T, name string) *container.StatsResponse {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stats container.StatsResponse
+	if err := json.Unmarshal(data, &stats); err != nil {
+		t.Fatalf("decode %s: %v", name, err)
+	}
+	return &stats
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestApplyStats(t *testing.T) {
+	tests := []struct {
+		fixture    string
+		limitCores float64
+
+		cpuPercent      float64
+		cpuQuotaPercent float64
+		memoryUsageMB   int
+		memoryLimitMB   int
+		cacheMB         int
+		rssMB           int
+		workingSetMB    int
+		pids            uint64
+		pidsLimit       uint64
+	}{
+		{
+			fixture:       "stats_cgroupv1.json",
+			cpuPercent:    50,
+			memoryUsageMB: 300,
+			memoryLimitMB: 1024,
+			cacheMB:       100,
+			rssMB:         190,
+			workingSetMB:  240,
+			pids:          23,
+		},
+		{
+			fixture:         "stats_cgroupv2.json",
+			limitCores:      2,
+			cpuPercent:      150,
+			cpuQuotaPercent: 75,
+			memoryUsageMB:   800,
+			memoryLimitMB:   2048,
+			cacheMB:         250,
+			rssMB:           540,
+			workingSetMB:    700,
+			pids:            41,
+			pidsLimit:       4096,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			stats := loadStats(t, tt.fixture)
+			c := &Client{rates: newRateTracker()}
+			metric := models.ContainerMetric{CPULimitCores: tt.limitCores}
+
+			c.applyStats(stats.ID, &metric, stats)
+
+			if !almostEqual(metric.CPUPercent, tt.cpuPercent) {
+				t.Errorf("CPUPercent = %v, want %v", metric.CPUPercent, tt.cpuPercent)
+			}
+			if !almostEqual(metric.CPUQuotaPercent, tt.cpuQuotaPercent) {
+				t.Errorf("CPUQuotaPercent = %v, want %v", metric.CPUQuotaPercent, tt.cpuQuotaPercent)
+			}
+			if metric.MemoryUsageMB != tt.memoryUsageMB || metric.MemoryLimitMB != tt.memoryLimitMB {
+				t.Errorf("memory = %d/%d MB, want %d/%d MB",
+					metric.MemoryUsageMB, metric.MemoryLimitMB, tt.memoryUsageMB, tt.memoryLimitMB)
+			}
+			if metric.MemoryCacheMB != tt.cacheMB || metric.MemoryRSSMB != tt.rssMB {
+				t.Errorf("cache/rss = %d/%d MB, want %d/%d MB",
+					metric.MemoryCacheMB, metric.MemoryRSSMB, tt.cacheMB, tt.rssMB)
+			}
+			if metric.MemoryWorkingSetMB != tt.workingSetMB {
+				t.Errorf("MemoryWorkingSetMB = %d, want %d", metric.MemoryWorkingSetMB, tt.workingSetMB)
+			}
+			if metric.PIDs != tt.pids || metric.PIDsLimit != tt.pidsLimit {
+				t.Errorf("pids = %d/%d, want %d/%d", metric.PIDs, metric.PIDsLimit, tt.pids, tt.pidsLimit)
+			}
+		})
+	}
+}
+
+func TestCPUPercentFallbacks(t *testing.T) {
+	// Daemons that predate online_cpus on cgroup v1
+	stats := loadStats(t, "stats_cgroupv1.json")
+	stats.CPUStats.OnlineCPUs = 0
+	if got := cpuPercent(stats); !almostEqual(got, 50) {
+		t.Errorf("percpu fallback: got %v, want 50", got)
+	}
+
+	// Neither online_cpus nor percpu_usage, as on old cgroup v2 daemons
+	stats = loadStats(t, "stats_cgroupv2.json")
+	stats.CPUStats.OnlineCPUs = 0
+	want := 1.5e9 / 4e9 * float64(runtime.NumCPU()) * 100
+	if got := cpuPercent(stats); !almostEqual(got, want) {
+		t.Errorf("NumCPU fallback: got %v, want %v", got, want)
+	}
+
+	// First streamed sample, nothing to compare against
+	stats = loadStats(t, "stats_cgroupv2.json")
+	stats.PreCPUStats = container.CPUStats{}
+	if got := cpuPercent(stats); got != 0 {
+		t.Errorf("first sample: got %v, want 0", got)
+	}
+
+	// Counters reset, e.g. the container restarted between samples
+	stats = loadStats(t, "stats_cgroupv2.json")
+	stats.PreCPUStats.CPUUsage.TotalUsage = stats.CPUStats.CPUUsage.TotalUsage + 1
+	if got := cpuPercent(stats); got != 0 {
+		t.Errorf("counter reset: got %v, want 0", got)
+	}
+}
+
+func TestCPULimitCores(t *testing.T) {
+	tests := []struct {
+		name string
+		res  container.Resources
+		want float64
+	}{
+		{"unlimited", container.Resources{}, 0},
+		{"cpus flag", container.Resources{NanoCPUs: 1500000000}, 1.5},
+		{"quota and period", container.Resources{CPUQuota: 50000, CPUPeriod: 100000}, 0.5},
+		{"quota with default period", container.Resources{CPUQuota: 200000}, 2},
+		{"cpus flag wins", container.Resources{NanoCPUs: 1000000000, CPUQuota: 400000}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cpuLimitCores(tt.res); !almostEqual(got, tt.want) {
+				t.Errorf("cpuLimitCores = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// internal/docker/stream.go
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"pulse_agent/pkg/logger"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/client"
+)
+
+// Samples older than this are ignored and the container is polled instead
+const streamStaleAfter = 30 * time.Second
+
+type statsStream struct {
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	latest *container.StatsResponse
+	at     time.Time
+}
+
+// streamManager keeps one streaming stats subscription per running
+// container so that a collection cycle only reads the latest sample.
+type streamManager struct {
+	cli *client.Client
+
+	mu      sync.Mutex
+	streams map[string]*statsStream
+}
+
+func newStreamManager(cli *client.Client) *streamManager {
+	return &streamManager{
+		cli:     cli,
+		streams: make(map[string]*statsStream),
+	}
+}
+
+// sync starts streams for newly running containers and stops streams
+// for containers that are no longer running
+func (m *streamManager) sync(running map[string]bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id := range running {
+		if _, ok := m.streams[id]; !ok {
+			m.start(id)
+		}
+	}
+
+	for id, s := range m.streams {
+		if !running[id] {
+			s.cancel()
+			delete(m.streams, id)
+		}
+	}
+}
+
+// ensure starts a stream for a container that just started
+func (m *streamManager) ensure(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.streams[id]; !ok {
+		m.start(id)
+	}
+}
+
+// start must be called with m.mu held
+func (m *streamManager) start(id string) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &statsStream{cancel: cancel}
+	m.streams[id] = s
+
+	go func() {
+		defer m.remove(id, s)
+
+		resp, err := m.cli.ContainerStats(ctx, id, true)
+		if err != nil {
+			logger.Debug("Stats stream for %.12s failed: %v", id, err)
+			return
+		}
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var stats container.StatsResponse
+			if err := decoder.Decode(&stats); err != nil {
+				// EOF when the container stops, canceled on sync/close
+				if ctx.Err() == nil {
+					logger.Debug("Stats stream for %.12s ended: %v", id, err)
+				}
+				return
+			}
+
+			s.mu.Lock()
+			s.latest = &stats
+			s.at = time.Now()
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// remove drops a finished stream so that the next sync can restart it
+func (m *streamManager) remove(id string, s *statsStream) {
+	s.cancel()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.streams[id] == s {
+		delete(m.streams, id)
+	}
+}
+
+func (m *streamManager) latest(id string) (*container.StatsResponse, bool) {
+	m.mu.Lock()
+	s, ok := m.streams[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.latest == nil || time.Since(s.at) > streamStaleAfter {
+		return nil, false
+	}
+	return s.latest, true
+}
+
+func (m *streamManager) closeAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.streams {
+		s.cancel()
+		delete(m.streams, id)
+	}
+}
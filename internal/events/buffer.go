@@ -0,0 +1,65 @@
+// internal/events/buffer.go
+package events
+
+import (
+	"sync"
+
+	"pulse_agent/internal/models"
+)
+
+const DefaultBufferSize = 1000
+
+// Buffer holds events until the next payload is sent. When full, the
+// oldest events are dropped.
+type Buffer struct {
+	mu     sync.Mutex
+	events []models.Event
+	max    int
+}
+
+func NewBuffer(max int) *Buffer {
+	if max <= 0 {
+		max = DefaultBufferSize
+	}
+	return &Buffer{max: max}
+}
+
+func (b *Buffer) Push(event models.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(b.events, event)
+	if over := len(b.events) - b.max; over > 0 {
+		b.events = b.events[over:]
+	}
+}
+
+// Drain returns all buffered events and empties the buffer
+func (b *Buffer) Drain() []models.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.events) == 0 {
+		return nil
+	}
+
+	drained := b.events
+	b.events = nil
+	return drained
+}
+
+// Requeue puts back events that could not be delivered, ahead of any
+// events buffered since. Oldest events are dropped first if full.
+func (b *Buffer) Requeue(events []models.Event) {
+	if len(events) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = append(append([]models.Event{}, events...), b.events...)
+	if over := len(b.events) - b.max; over > 0 {
+		b.events = b.events[over:]
+	}
+}
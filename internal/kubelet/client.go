@@ -0,0 +1,157 @@
+// internal/kubelet/client.go
+package kubelet
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// Reconnect attempts while the kubelet is unreachable
+	retryInterval = 30 * time.Second
+)
+
+// Client reads pod and container metrics from the kubelet of the node
+// the agent runs on. The service account needs get on nodes/stats and
+// nodes/proxy.
+type Client struct {
+	cfg  config.KubeletConfig
+	http *http.Client
+
+	available atomic.Bool
+	lastRetry atomic.Int64 // unix nanoseconds
+
+	mu   sync.Mutex
+	pods []models.PodMetric
+}
+
+func NewClient(cfg *config.Config) (*Client, error) {
+	kcfg := cfg.Runtime.Kubelet
+	if kcfg.URL == "" {
+		return nil, errors.New("AGENT_KUBELET_URL or NODE_NAME must be set in Kubernetes mode")
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: kcfg.InsecureSkipVerify}
+	if !kcfg.InsecureSkipVerify {
+		// Without the cluster CA the system roots are used
+		if pem, err := os.ReadFile(kcfg.CAFile); err == nil {
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates in %s", kcfg.CAFile)
+			}
+			tlsConfig.RootCAs = pool
+		}
+	}
+
+	c := &Client{
+		cfg: kcfg,
+		http: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+
+	if err := c.ping(context.Background()); err != nil {
+		logger.Warn("Kubelet not available at %s, will retry: %v", kcfg.URL, err)
+		c.lastRetry.Store(time.Now().UnixNano())
+	} else {
+		logger.Info("Kubelet client connected successfully (%s)", kcfg.URL)
+		c.available.Store(true)
+	}
+
+	return c, nil
+}
+
+func (c *Client) Name() string {
+	return config.RuntimeKubernetes
+}
+
+// IsAvailable asks the kubelet again, at most every retryInterval,
+// while it is unreachable
+func (c *Client) IsAvailable() bool {
+	if c.available.Load() {
+		return true
+	}
+
+	now := time.Now()
+	if now.Sub(time.Unix(0, c.lastRetry.Load())) < retryInterval {
+		return false
+	}
+	c.lastRetry.Store(now.UnixNano())
+
+	if err := c.ping(context.Background()); err != nil {
+		return false
+	}
+
+	logger.Info("Kubelet connected, pod metrics resumed")
+	c.available.Store(true)
+	return true
+}
+
+func (c *Client) Close() error {
+	c.http.CloseIdleConnections()
+	return nil
+}
+
+// TakePods returns the pods of the last GetContainerStats call once
+func (c *Client) TakePods() []models.PodMetric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pods := c.pods
+	c.pods = nil
+	return pods
+}
+
+func (c *Client) ping(ctx context.Context) error {
+	return c.get(ctx, "/healthz", nil)
+}
+
+// get decodes a kubelet JSON response into v, or discards it if v is nil
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(c.cfg.URL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+
+	// Projected service account tokens rotate, read it every time
+	if token, err := os.ReadFile(c.cfg.TokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if v == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: %w", path, err)
+	}
+	return nil
+}
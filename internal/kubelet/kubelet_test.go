@@ -0,0 +1,208 @@
+// internal/kubelet/kubelet_test.go
+package kubelet
+
+import (
+	"context"
+	"encoding/pem"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+)
+
+const testToken = "test-token"
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+// newKubelet serves the hand-written /pods and /stats/summary fixtures
+// like the kubelet's secure port, rejecting requests without the
+// service account token
+func newKubelet(t *testing.T) *config.Config {
+	t.Helper()
+
+	routes := map[string]string{
+		"/pods":          "pods.json",
+		"/stats/summary": "summary.json",
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/healthz" {
+			w.Write([]byte("ok"))
+			return
+		}
+
+		fixture, ok := routes[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	caFile := filepath.Join(dir, "ca.crt")
+
+	if err := os.WriteFile(tokenFile, []byte(testToken+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Runtime: config.RuntimeConfig{Kubelet: config.KubeletConfig{
+		URL:       srv.URL,
+		NodeName:  "node-1",
+		TokenFile: tokenFile,
+		CAFile:    caFile,
+	}}}
+	return cfg
+}
+
+func TestGetContainerStats(t *testing.T) {
+	cfg := newKubelet(t)
+
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsAvailable() {
+		t.Fatal("kubelet not available")
+	}
+
+	containers, err := c.GetContainerStats(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 4 {
+		t.Fatalf("got %d containers, want 4", len(containers))
+	}
+
+	byName := make(map[string]models.ContainerMetric)
+	for _, ctr := range containers {
+		byName[ctr.Name] = ctr
+	}
+
+	nginx := byName["web-7d9c8b6f5-x2k4p/nginx"]
+	if nginx.ID != "8f2e4c1d9a7b" || nginx.State != "running" {
+		t.Errorf("nginx id/state = %q/%q", nginx.ID, nginx.State)
+	}
+	if !almostEqual(nginx.CPUPercent, 12.5) || !almostEqual(nginx.CPUQuotaPercent, 25) {
+		t.Errorf("nginx cpu = %v%%, quota %v%%, want 12.5%%, 25%%", nginx.CPUPercent, nginx.CPUQuotaPercent)
+	}
+	if nginx.MemoryUsageMB != 100 || nginx.MemoryWorkingSetMB != 80 || nginx.MemoryRSSMB != 60 || nginx.MemoryLimitMB != 256 {
+		t.Errorf("nginx memory = %d/%d/%d MB, limit %d MB",
+			nginx.MemoryUsageMB, nginx.MemoryWorkingSetMB, nginx.MemoryRSSMB, nginx.MemoryLimitMB)
+	}
+	wantLabels := map[string]string{
+		labelNamespace: "default",
+		labelPod:       "web-7d9c8b6f5-x2k4p",
+		labelContainer: "nginx",
+		labelNode:      "node-1",
+	}
+	for key, want := range wantLabels {
+		if got := nginx.Labels[key]; got != want {
+			t.Errorf("nginx label %s = %q, want %q", key, got, want)
+		}
+	}
+
+	shipper := byName["web-7d9c8b6f5-x2k4p/log-shipper"]
+	if shipper.RestartCount != 3 || !shipper.OOMKilled || shipper.ExitCode != 137 {
+		t.Errorf("log-shipper restarts/oom/exit = %d/%v/%d, want 3/true/137",
+			shipper.RestartCount, shipper.OOMKilled, shipper.ExitCode)
+	}
+
+	report := byName["report-28512340-abcde/report"]
+	if report.State != "waiting" || report.Status != "ImagePullBackOff" || report.CPUPercent != 0 {
+		t.Errorf("report state/status/cpu = %q/%q/%v", report.State, report.Status, report.CPUPercent)
+	}
+
+	pods := c.TakePods()
+	if len(pods) != 3 {
+		t.Fatalf("got %d pods, want 3", len(pods))
+	}
+	if c.TakePods() != nil {
+		t.Error("TakePods returned pods twice")
+	}
+
+	web := pods[0]
+	if web.Phase != "Running" || web.Node != "node-1" || web.ContainerCount != 2 || web.ReadyCount != 2 || web.RestartCount != 3 {
+		t.Errorf("web pod = %+v", web)
+	}
+	if !almostEqual(web.CPUPercent, 13) || web.MemoryUsageMB != 120 || web.WorkingSetMB != 95 {
+		t.Errorf("web pod cpu/memory = %v%%, %d/%d MB", web.CPUPercent, web.MemoryUsageMB, web.WorkingSetMB)
+	}
+	if !almostEqual(web.NetworkRxMB, 10) || !almostEqual(web.NetworkTxMB, 5) {
+		t.Errorf("web pod network = %v/%v MB", web.NetworkRxMB, web.NetworkTxMB)
+	}
+
+	if pending := pods[2]; pending.Phase != "Pending" || pending.ReadyCount != 0 {
+		t.Errorf("pending pod = %+v", pending)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	cfg := newKubelet(t)
+	cfg.Runtime.Kubelet.TokenFile = filepath.Join(t.TempDir(), "missing")
+
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.IsAvailable() {
+		t.Error("available without a token")
+	}
+	if _, err := c.GetContainerStats(context.Background()); err == nil {
+		t.Error("expected an error without a token")
+	}
+}
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"500m", 0.5},
+		{"2", 2},
+		{"1.5", 1.5},
+		{"256Mi", 256 << 20},
+		{"1Gi", 1 << 30},
+		{"128M", 128e6},
+		{"1e3", 1000},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseQuantity(tt.in)
+		if !ok || !almostEqual(got, tt.want) {
+			t.Errorf("parseQuantity(%q) = %v, %v, want %v", tt.in, got, ok, tt.want)
+		}
+	}
+
+	if _, ok := parseQuantity(""); ok {
+		t.Error("empty quantity parsed")
+	}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
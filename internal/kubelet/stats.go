@@ -0,0 +1,203 @@
+// internal/kubelet/stats.go
+package kubelet
+
+import (
+	"context"
+	"strings"
+
+	"pulse_agent/internal/docker"
+	"pulse_agent/internal/models"
+)
+
+// Labels attached to every container, named like the ones the CRI
+// plugin sets on the containers themselves
+const (
+	labelNamespace = "io.kubernetes.pod.namespace"
+	labelPod       = "io.kubernetes.pod.name"
+	labelContainer = "io.kubernetes.container.name"
+	labelNode      = "kubernetes.io/hostname"
+)
+
+// GetContainerStats merges the pod list, which has phases, restart
+// counts and limits, with the stats summary. Pods are kept for TakePods.
+func (c *Client) GetContainerStats(ctx context.Context) ([]models.ContainerMetric, error) {
+	var pods podList
+	if err := c.get(ctx, "/pods", &pods); err != nil {
+		c.available.Store(false)
+		return nil, err
+	}
+
+	var sum summary
+	if err := c.get(ctx, "/stats/summary", &sum); err != nil {
+		c.available.Store(false)
+		return nil, err
+	}
+
+	containers, podMetrics := merge(pods, sum, c.cfg.NodeName)
+
+	c.mu.Lock()
+	c.pods = podMetrics
+	c.mu.Unlock()
+
+	return containers, nil
+}
+
+func merge(pods podList, sum summary, nodeName string) ([]models.ContainerMetric, []models.PodMetric) {
+	if sum.Node.NodeName != "" {
+		nodeName = sum.Node.NodeName
+	}
+
+	statsByUID := make(map[string]podStats, len(sum.Pods))
+	for _, ps := range sum.Pods {
+		statsByUID[ps.PodRef.UID] = ps
+	}
+
+	var (
+		containers []models.ContainerMetric
+		podMetrics []models.PodMetric
+	)
+	for _, p := range pods.Items {
+		node := p.Spec.NodeName
+		if node == "" {
+			node = nodeName
+		}
+		ps := statsByUID[p.Metadata.UID]
+
+		pm := models.PodMetric{
+			Namespace:      p.Metadata.Namespace,
+			Name:           p.Metadata.Name,
+			UID:            p.Metadata.UID,
+			Node:           node,
+			Phase:          p.Status.Phase,
+			Reason:         p.Status.Reason,
+			ContainerCount: len(p.Spec.Containers),
+		}
+		if p.Status.StartTime != nil {
+			pm.StartedAt = *p.Status.StartTime
+		}
+		applyPodStats(&pm, ps)
+
+		statuses := make(map[string]containerStatus, len(p.Status.ContainerStatuses))
+		for _, cs := range p.Status.ContainerStatuses {
+			statuses[cs.Name] = cs
+			pm.RestartCount += cs.RestartCount
+			if cs.Ready {
+				pm.ReadyCount++
+			}
+		}
+
+		stats := make(map[string]containerStats, len(ps.Containers))
+		for _, cs := range ps.Containers {
+			stats[cs.Name] = cs
+		}
+
+		for _, spec := range p.Spec.Containers {
+			metric := models.ContainerMetric{
+				Name:  p.Metadata.Name + "/" + spec.Name,
+				Image: spec.Image,
+				State: "waiting",
+				Labels: map[string]string{
+					labelNamespace: p.Metadata.Namespace,
+					labelPod:       p.Metadata.Name,
+					labelContainer: spec.Name,
+					labelNode:      node,
+				},
+			}
+
+			if cores, ok := parseQuantity(spec.Resources.Limits["cpu"]); ok {
+				metric.CPULimitCores = cores
+			}
+			if bytes, ok := parseQuantity(spec.Resources.Limits["memory"]); ok {
+				metric.MemoryLimitMB = int(bytes / 1024 / 1024)
+			}
+
+			if cs, ok := statuses[spec.Name]; ok {
+				applyStatus(&metric, cs)
+			}
+			if cs, ok := stats[spec.Name]; ok {
+				applyContainerStats(&metric, cs)
+			}
+
+			containers = append(containers, metric)
+		}
+
+		podMetrics = append(podMetrics, pm)
+	}
+
+	return containers, podMetrics
+}
+
+func applyStatus(metric *models.ContainerMetric, cs containerStatus) {
+	// The ID is "<runtime>://<id>"
+	if _, id, ok := strings.Cut(cs.ContainerID, "://"); ok {
+		metric.ID = docker.ShortID(id)
+	}
+	if cs.Image != "" {
+		metric.Image = cs.Image
+	}
+	metric.RestartCount = cs.RestartCount
+
+	switch s := cs.State; {
+	case s.Running != nil:
+		metric.State = "running"
+		metric.Status = "Running"
+		metric.CreatedAt = s.Running.StartedAt
+	case s.Terminated != nil:
+		metric.State = "terminated"
+		metric.Status = s.Terminated.Reason
+		metric.ExitCode = s.Terminated.ExitCode
+	case s.Waiting != nil:
+		metric.Status = s.Waiting.Reason
+	}
+
+	// A running container that restarted keeps why it died last time
+	if last := cs.LastState.Terminated; last != nil {
+		if cs.State.Terminated == nil {
+			metric.ExitCode = last.ExitCode
+		}
+		metric.OOMKilled = last.Reason == "OOMKilled"
+	}
+	if t := cs.State.Terminated; t != nil && t.Reason == "OOMKilled" {
+		metric.OOMKilled = true
+	}
+}
+
+func applyContainerStats(metric *models.ContainerMetric, cs containerStats) {
+	if cpu := cs.CPU; cpu != nil && cpu.UsageNanoCores != nil {
+		// Relative to one core, like the Docker metrics
+		metric.CPUPercent = float64(*cpu.UsageNanoCores) / 1e7
+		if metric.CPULimitCores > 0 {
+			metric.CPUQuotaPercent = metric.CPUPercent / metric.CPULimitCores
+		}
+	}
+	if mem := cs.Memory; mem != nil {
+		metric.MemoryUsageMB = toMB(mem.UsageBytes)
+		metric.MemoryWorkingSetMB = toMB(mem.WorkingSetBytes)
+		metric.MemoryRSSMB = toMB(mem.RSSBytes)
+	}
+}
+
+func applyPodStats(pm *models.PodMetric, ps podStats) {
+	if cpu := ps.CPU; cpu != nil && cpu.UsageNanoCores != nil {
+		pm.CPUPercent = float64(*cpu.UsageNanoCores) / 1e7
+	}
+	if mem := ps.Memory; mem != nil {
+		pm.MemoryUsageMB = toMB(mem.UsageBytes)
+		pm.WorkingSetMB = toMB(mem.WorkingSetBytes)
+	}
+	if net := ps.Network; net != nil {
+		if net.RxBytes != nil {
+			pm.NetworkRxMB = float64(*net.RxBytes) / 1024 / 1024
+		}
+		if net.TxBytes != nil {
+			pm.NetworkTxMB = float64(*net.TxBytes) / 1024 / 1024
+		}
+	}
+}
+
+func toMB(bytes *uint64) int {
+	if bytes == nil {
+		return 0
+	}
+	return int(*bytes / 1024 / 1024)
+}
@@ -0,0 +1,98 @@
+// internal/kubelet/types.go
+package kubelet
+
+import "time"
+
+// The subset of the kubelet /stats/summary response the agent uses
+type summary struct {
+	Node struct {
+		NodeName string `json:"nodeName"`
+	} `json:"node"`
+	Pods []podStats `json:"pods"`
+}
+
+type podStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		UID       string `json:"uid"`
+	} `json:"podRef"`
+	Containers []containerStats `json:"containers"`
+	CPU        *cpuStats        `json:"cpu"`
+	Memory     *memoryStats     `json:"memory"`
+	Network    *networkStats    `json:"network"`
+}
+
+type containerStats struct {
+	Name   string       `json:"name"`
+	CPU    *cpuStats    `json:"cpu"`
+	Memory *memoryStats `json:"memory"`
+}
+
+type cpuStats struct {
+	UsageNanoCores *uint64 `json:"usageNanoCores"`
+}
+
+type memoryStats struct {
+	UsageBytes      *uint64 `json:"usageBytes"`
+	WorkingSetBytes *uint64 `json:"workingSetBytes"`
+	RSSBytes        *uint64 `json:"rssBytes"`
+}
+
+// networkStats holds the totals of the pod's default interface
+type networkStats struct {
+	RxBytes *uint64 `json:"rxBytes"`
+	TxBytes *uint64 `json:"txBytes"`
+}
+
+// The subset of the kubelet /pods response (a core/v1 PodList)
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+type pod struct {
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+		UID       string `json:"uid"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name      string `json:"name"`
+			Image     string `json:"image"`
+			Resources struct {
+				Limits map[string]string `json:"limits"`
+			} `json:"resources"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		Phase             string            `json:"phase"`
+		Reason            string            `json:"reason"`
+		StartTime         *time.Time        `json:"startTime"`
+		ContainerStatuses []containerStatus `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type containerStatus struct {
+	Name         string         `json:"name"`
+	ContainerID  string         `json:"containerID"` // e.g. containerd://<id>
+	Image        string         `json:"image"`
+	Ready        bool           `json:"ready"`
+	RestartCount int            `json:"restartCount"`
+	State        containerState `json:"state"`
+	LastState    containerState `json:"lastState"`
+}
+
+type containerState struct {
+	Running *struct {
+		StartedAt time.Time `json:"startedAt"`
+	} `json:"running"`
+	Waiting *struct {
+		Reason string `json:"reason"`
+	} `json:"waiting"`
+	Terminated *struct {
+		ExitCode int    `json:"exitCode"`
+		Reason   string `json:"reason"`
+	} `json:"terminated"`
+}
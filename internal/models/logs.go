@@ -0,0 +1,20 @@
+// internal/models/logs.go
+package models
+
+import "time"
+
+type LogEntry struct {
+	ContainerID   string    `json:"container_id"`
+	ContainerName string    `json:"container_name"`
+	Image         string    `json:"image"`
+	Stream        string    `json:"stream"` // stdout | stderr
+	Message       string    `json:"message"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+type LogBatch struct {
+	ServerID string     `json:"server_id"`
+	Entries  []LogEntry `json:"entries"`
+	// Lines dropped by the rate limiter since the previous batch
+	Dropped int `json:"dropped"`
+}
@@ -4,12 +4,23 @@ package models
 import "time"
 
 type Payload struct {
-	ServerID       string            `json:"server_id"`
-	Environment    string            `json:"environment"`
-	Timestamp      time.Time         `json:"timestamp"`
-	System         *SystemMetric     `json:"system"`
-	Containers     []ContainerMetric `json:"containers"`
-	ContainerCount int               `json:"container_count"`
+	ServerID         string                 `json:"server_id"`
+	Environment      string                 `json:"environment"`
+	Timestamp        time.Time              `json:"timestamp"`
+	System           *SystemMetric          `json:"system"`
+	DockerAvailable  bool                   `json:"docker_available"`
+	ContainerRuntime string                 `json:"container_runtime,omitempty"`
+	RuntimeAvailable bool                   `json:"runtime_available"` // any runtime, Docker included
+	Containers       []ContainerMetric      `json:"containers"`
+	ContainerCount   int                    `json:"container_count"`
+	ComposeProjects  []ComposeProjectMetric `json:"compose_projects,omitempty"`
+	Pods             []PodMetric            `json:"pods,omitempty"`
+	DockerDisk       *DockerDiskMetric      `json:"docker_disk,omitempty"`
+	Kernel           *KernelMetric          `json:"kernel,omitempty"`
+	Sockets          *SocketMetric          `json:"sockets,omitempty"`
+	Sensors          []SensorMetric         `json:"sensors,omitempty"`
+	Services         []ServiceMetric        `json:"services,omitempty"`
+	Events           []Event                `json:"events,omitempty"`
 }
 
 type SystemMetric struct {
@@ -26,18 +37,254 @@ type SystemMetric struct {
 	DiskTotalGB   int     `json:"disk_total_gb"`
 	DiskUsedGB    int     `json:"disk_used_gb"`
 	DiskPercent   float64 `json:"disk_percent"`
+
+	MemoryAvailableMB  int     `json:"memory_available_mb"`
+	MemoryCachedMB     int     `json:"memory_cached_mb"`
+	MemoryBuffersMB    int     `json:"memory_buffers_mb"`
+	SwapTotalMB        int     `json:"swap_total_mb"`
+	SwapUsedMB         int     `json:"swap_used_mb"`
+	SwapPercent        float64 `json:"swap_percent"`
+	SwapInBytesPerSec  float64 `json:"swap_in_bytes_per_sec"`
+	SwapOutBytesPerSec float64 `json:"swap_out_bytes_per_sec"`
+
+	Pressure *PressureMetric `json:"pressure,omitempty"`
+}
+
+// PressureMetric holds Linux pressure stall information (/proc/pressure)
+type PressureMetric struct {
+	CPU    *PressureStat `json:"cpu,omitempty"`
+	Memory *PressureStat `json:"memory,omitempty"`
+	IO     *PressureStat `json:"io,omitempty"`
+}
+
+// PressureStat averages are percentages of wall time, totals are microseconds
+type PressureStat struct {
+	SomeAvg10  float64 `json:"some_avg10"`
+	SomeAvg60  float64 `json:"some_avg60"`
+	SomeAvg300 float64 `json:"some_avg300"`
+	SomeTotal  uint64  `json:"some_total_us"`
+	FullAvg10  float64 `json:"full_avg10"`
+	FullAvg60  float64 `json:"full_avg60"`
+	FullAvg300 float64 `json:"full_avg300"`
+	FullTotal  uint64  `json:"full_total_us"`
+}
+
+// KernelMetric holds kernel and OS counters read from /proc
+type KernelMetric struct {
+	ContextSwitchesPerSec float64 `json:"context_switches_per_sec"`
+	InterruptsPerSec      float64 `json:"interrupts_per_sec"`
+	ForksPerSec           float64 `json:"forks_per_sec"`
+	ProcsRunning          int     `json:"procs_running"`
+	ProcsBlocked          int     `json:"procs_blocked"`
+	OpenFiles             uint64  `json:"open_files"`
+	OpenFilesMax          uint64  `json:"open_files_max"`
+	OpenFilesPercent      float64 `json:"open_files_percent"`
+	EntropyAvailable      int     `json:"entropy_available"`
+	ConntrackCount        uint64  `json:"conntrack_count,omitempty"`
+	ConntrackMax          uint64  `json:"conntrack_max,omitempty"`
+	ConntrackPercent      float64 `json:"conntrack_percent,omitempty"`
+}
+
+// SocketMetric summarises TCP/UDP socket state and listening ports
+type SocketMetric struct {
+	TCPStates             map[string]int  `json:"tcp_states"`
+	TCPConnections        int             `json:"tcp_connections"`
+	TCPOutSegsPerSec      float64         `json:"tcp_out_segs_per_sec"`
+	TCPRetransSegsPerSec  float64         `json:"tcp_retrans_segs_per_sec"`
+	TCPRetransPercent     float64         `json:"tcp_retrans_percent"`
+	TCPInErrorsPerSec     float64         `json:"tcp_in_errors_per_sec"`
+	TCPResetsPerSec       float64         `json:"tcp_resets_per_sec"`
+	UDPInDatagramsPerSec  float64         `json:"udp_in_datagrams_per_sec"`
+	UDPOutDatagramsPerSec float64         `json:"udp_out_datagrams_per_sec"`
+	UDPInErrorsPerSec     float64         `json:"udp_in_errors_per_sec"`
+	UDPNoPortsPerSec      float64         `json:"udp_no_ports_per_sec"`
+	Listeners             []ListeningPort `json:"listeners"`
+}
+
+type ListeningPort struct {
+	Protocol string `json:"protocol"` // tcp | tcp6 | udp | udp6
+	Address  string `json:"address"`
+	Port     uint32 `json:"port"`
+	PID      int32  `json:"pid,omitempty"`
+	Process  string `json:"process,omitempty"`
+}
+
+// SensorMetric is a single hardware sensor reading. Temperatures are in
+// celsius, fans in RPM. Thresholds are omitted when the driver lacks them.
+type SensorMetric struct {
+	Name     string  `json:"name"`
+	Kind     string  `json:"kind"`     // temperature | fan
+	Category string  `json:"category"` // cpu | nvme | disk | gpu | other
+	Value    float64 `json:"value"`
+	Unit     string  `json:"unit"` // celsius | rpm
+	Min      float64 `json:"min,omitempty"`
+	High     float64 `json:"high,omitempty"`
+	Critical float64 `json:"critical,omitempty"`
 }
 
 type ContainerMetric struct {
-	ID            string    `json:"id"`
-	Name          string    `json:"name"`
-	Image         string    `json:"image"`
-	State         string    `json:"state"`
-	Status        string    `json:"status"`
-	CreatedAt     time.Time `json:"created_at"`
-	CPUPercent    float64   `json:"cpu_percent"`
-	MemoryUsageMB int       `json:"memory_usage_mb"`
-	MemoryLimitMB int       `json:"memory_limit_mb"`
-	NetworkRxMB   float64   `json:"network_rx_mb"`
-	NetworkTxMB   float64   `json:"network_tx_mb"`
+	ID             string            `json:"id"`
+	Name           string            `json:"name"`
+	Image          string            `json:"image"`
+	State          string            `json:"state"`
+	Status         string            `json:"status"`
+	CreatedAt      time.Time         `json:"created_at"`
+	Labels         map[string]string `json:"labels,omitempty"`
+	ComposeProject string            `json:"compose_project,omitempty"`
+	ComposeService string            `json:"compose_service,omitempty"`
+	CPUPercent     float64           `json:"cpu_percent"`
+	MemoryUsageMB  int               `json:"memory_usage_mb"`
+	MemoryLimitMB  int               `json:"memory_limit_mb"`
+	NetworkRxMB    float64           `json:"network_rx_mb"`
+	NetworkTxMB    float64           `json:"network_tx_mb"`
+	StatsError     string            `json:"stats_error,omitempty"`
+
+	// CPUPercent is relative to one core (200 = two busy cores). With a
+	// --cpus or quota limit, CPUQuotaPercent is relative to that limit.
+	CPULimitCores   float64 `json:"cpu_limit_cores,omitempty"`
+	CPUQuotaPercent float64 `json:"cpu_quota_percent,omitempty"`
+
+	// Memory breakdown; working set is usage minus inactive page cache
+	MemoryCacheMB      int `json:"memory_cache_mb"`
+	MemoryRSSMB        int `json:"memory_rss_mb"`
+	MemoryWorkingSetMB int `json:"memory_working_set_mb"`
+
+	PIDs      uint64 `json:"pids"`
+	PIDsLimit uint64 `json:"pids_limit"`
+
+	// Rates are computed between two consecutive samples
+	CPUThrottledPeriodsPerSec float64 `json:"cpu_throttled_periods_per_sec"`
+	CPUThrottledMsPerSec      float64 `json:"cpu_throttled_ms_per_sec"`
+	BlockReadBytesPerSec      float64 `json:"block_read_bytes_per_sec"`
+	BlockWriteBytesPerSec     float64 `json:"block_write_bytes_per_sec"`
+	NetworkRxBytesPerSec      float64 `json:"network_rx_bytes_per_sec"`
+	NetworkTxBytesPerSec      float64 `json:"network_tx_bytes_per_sec"`
+
+	Networks []ContainerNetworkMetric `json:"networks,omitempty"`
+
+	// From container inspect
+	Health              string `json:"health,omitempty"` // starting | healthy | unhealthy
+	HealthFailingStreak int    `json:"health_failing_streak,omitempty"`
+	LastHealthOutput    string `json:"last_health_output,omitempty"`
+	RestartCount        int    `json:"restart_count"`
+	RestartPolicy       string `json:"restart_policy,omitempty"`
+	OOMKilled           bool   `json:"oom_killed"`
+	ExitCode            int    `json:"exit_code"`
+}
+
+type ContainerNetworkMetric struct {
+	Name            string  `json:"name"`
+	RxBytesPerSec   float64 `json:"rx_bytes_per_sec"`
+	TxBytesPerSec   float64 `json:"tx_bytes_per_sec"`
+	RxPacketsPerSec float64 `json:"rx_packets_per_sec"`
+	TxPacketsPerSec float64 `json:"tx_packets_per_sec"`
+	RxErrorsPerSec  float64 `json:"rx_errors_per_sec"`
+	TxErrorsPerSec  float64 `json:"tx_errors_per_sec"`
+	RxDroppedPerSec float64 `json:"rx_dropped_per_sec"`
+	TxDroppedPerSec float64 `json:"tx_dropped_per_sec"`
+}
+
+type ServiceMetric struct {
+	Name          string  `json:"name"`
+	Source        string  `json:"source"` // systemd | process
+	Running       bool    `json:"running"`
+	State         string  `json:"state"`
+	SubState      string  `json:"sub_state,omitempty"`
+	PID           int32   `json:"pid"`
+	ProcessCount  int     `json:"process_count"`
+	RestartCount  int     `json:"restart_count"`
+	UptimeSeconds uint64  `json:"uptime_seconds"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryUsageMB int     `json:"memory_usage_mb"`
+}
+
+// Event is a point-in-time occurrence (service down, container died, ...)
+// reported alongside the periodic metrics.
+type Event struct {
+	Type       string            `json:"type"`
+	Source     string            `json:"source"`
+	Subject    string            `json:"subject"`
+	Message    string            `json:"message"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Timestamp  time.Time         `json:"timestamp"`
+}
+
+// ComposeProjectMetric aggregates the containers of a Docker Compose
+// project (com.docker.compose.project label)
+type ComposeProjectMetric struct {
+	Project        string                 `json:"project"`
+	ContainerCount int                    `json:"container_count"`
+	RunningCount   int                    `json:"running_count"`
+	UnhealthyCount int                    `json:"unhealthy_count"`
+	CPUPercent     float64                `json:"cpu_percent"`
+	MemoryUsageMB  int                    `json:"memory_usage_mb"`
+	Services       []ComposeServiceMetric `json:"services"`
+}
+
+type ComposeServiceMetric struct {
+	Service        string  `json:"service"`
+	ContainerCount int     `json:"container_count"`
+	RunningCount   int     `json:"running_count"`
+	UnhealthyCount int     `json:"unhealthy_count"`
+	CPUPercent     float64 `json:"cpu_percent"`
+	MemoryUsageMB  int     `json:"memory_usage_mb"`
+}
+
+// PodMetric is a Kubernetes pod on this node, from the kubelet. Its
+// containers are reported in Containers with io.kubernetes.* labels.
+type PodMetric struct {
+	Namespace      string    `json:"namespace"`
+	Name           string    `json:"name"`
+	UID            string    `json:"uid"`
+	Node           string    `json:"node"`
+	Phase          string    `json:"phase"` // Pending | Running | Succeeded | Failed | Unknown
+	Reason         string    `json:"reason,omitempty"`
+	ContainerCount int       `json:"container_count"`
+	ReadyCount     int       `json:"ready_count"`
+	RestartCount   int       `json:"restart_count"`
+	CPUPercent     float64   `json:"cpu_percent"`
+	MemoryUsageMB  int       `json:"memory_usage_mb"`
+	WorkingSetMB   int       `json:"memory_working_set_mb"`
+	NetworkRxMB    float64   `json:"network_rx_mb"`
+	NetworkTxMB    float64   `json:"network_tx_mb"`
+	StartedAt      time.Time `json:"started_at"`
+}
+
+// DockerDiskMetric is the Docker "system df" summary. It is collected on
+// a slower interval and only present in the payload after a refresh.
+type DockerDiskMetric struct {
+	CollectedAt time.Time `json:"collected_at"`
+
+	ImageCount          int     `json:"image_count"`
+	ImagesSizeMB        float64 `json:"images_size_mb"`
+	ImagesReclaimableMB float64 `json:"images_reclaimable_mb"`
+	DanglingImageCount  int     `json:"dangling_image_count"`
+	DanglingImagesMB    float64 `json:"dangling_images_mb"`
+
+	ContainersSizeMB        float64 `json:"containers_size_mb"`
+	ContainersReclaimableMB float64 `json:"containers_reclaimable_mb"`
+
+	VolumeCount          int     `json:"volume_count"`
+	VolumesSizeMB        float64 `json:"volumes_size_mb"`
+	VolumesReclaimableMB float64 `json:"volumes_reclaimable_mb"`
+
+	BuildCacheSizeMB        float64 `json:"build_cache_size_mb"`
+	BuildCacheReclaimableMB float64 `json:"build_cache_reclaimable_mb"`
+
+	Volumes    []DockerVolumeMetric       `json:"volumes"`
+	Containers []ContainerDiskUsageMetric `json:"containers"`
+}
+
+type DockerVolumeMetric struct {
+	Name     string  `json:"name"`
+	Driver   string  `json:"driver"`
+	SizeMB   float64 `json:"size_mb"` // -1 when the driver cannot report it
+	RefCount int64   `json:"ref_count"`
+}
+
+type ContainerDiskUsageMetric struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	State           string  `json:"state"`
+	WritableLayerMB float64 `json:"writable_layer_mb"`
 }
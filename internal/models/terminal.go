@@ -0,0 +1,18 @@
+// internal/models/terminal.go
+package models
+
+import "time"
+
+// TerminalRecording is a finished terminal session in asciicast v2
+// format, uploaded to the backend
+type TerminalRecording struct {
+	ServerID    string    `json:"server_id"`
+	SessionID   string    `json:"session_id"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Container   string    `json:"container,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at"`
+	// Keystrokes were recorded as well as output
+	Input bool   `json:"input"`
+	Cast  string `json:"cast"`
+}
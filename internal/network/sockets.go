@@ -0,0 +1,176 @@
+// internal/network/sockets.go
+package network
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"syscall"
+	"time"
+
+	"pulse_agent/internal/counter"
+	"pulse_agent/internal/events"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+
+	"github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+type snmpCounters struct {
+	tcp map[string]int64
+	udp map[string]int64
+	at  time.Time
+}
+
+// SocketCollector reports TCP state counts, TCP/UDP error rates from
+// /proc/net/snmp and the inventory of listening ports.
+type SocketCollector struct {
+	events    *events.Buffer
+	last      *snmpCounters
+	listeners map[string]bool // nil until the first inventory
+}
+
+func NewSocketCollector(eventBuf *events.Buffer) *SocketCollector {
+	return &SocketCollector{events: eventBuf}
+}
+
+func (c *SocketCollector) GetSocketStats(ctx context.Context) (*models.SocketMetric, error) {
+	conns, err := net.ConnectionsWithoutUidsWithContext(ctx, "inet")
+	if err != nil {
+		return nil, err
+	}
+
+	metric := &models.SocketMetric{
+		TCPStates: make(map[string]int),
+		Listeners: []models.ListeningPort{},
+	}
+
+	names := make(map[int32]string)
+	for _, conn := range conns {
+		switch conn.Type {
+		case syscall.SOCK_STREAM:
+			metric.TCPStates[conn.Status]++
+			metric.TCPConnections++
+			if conn.Status == "LISTEN" {
+				metric.Listeners = append(metric.Listeners, listener(ctx, conn, "tcp", names))
+			}
+		case syscall.SOCK_DGRAM:
+			// Unconnected UDP sockets are the UDP equivalent of listeners
+			if conn.Raddr.Port == 0 {
+				metric.Listeners = append(metric.Listeners, listener(ctx, conn, "udp", names))
+			}
+		}
+	}
+
+	sort.Slice(metric.Listeners, func(i, j int) bool {
+		a, b := metric.Listeners[i], metric.Listeners[j]
+		if a.Port != b.Port {
+			return a.Port < b.Port
+		}
+		if a.Protocol != b.Protocol {
+			return a.Protocol < b.Protocol
+		}
+		return a.Address < b.Address
+	})
+
+	c.trackListeners(metric.Listeners)
+
+	if err := c.addProtoRates(ctx, metric); err != nil {
+		logger.Debug("Failed to read protocol counters: %v", err)
+	}
+
+	return metric, nil
+}
+
+func listener(ctx context.Context, conn net.ConnectionStat, proto string, names map[int32]string) models.ListeningPort {
+	if conn.Family == syscall.AF_INET6 {
+		proto += "6"
+	}
+
+	port := models.ListeningPort{
+		Protocol: proto,
+		Address:  conn.Laddr.IP,
+		Port:     conn.Laddr.Port,
+		PID:      conn.Pid,
+	}
+
+	if conn.Pid > 0 {
+		name, ok := names[conn.Pid]
+		if !ok {
+			if p, err := process.NewProcessWithContext(ctx, conn.Pid); err == nil {
+				name, _ = p.NameWithContext(ctx)
+			}
+			names[conn.Pid] = name
+		}
+		port.Process = name
+	}
+
+	return port
+}
+
+// trackListeners emits an event for every listener that appeared since
+// the previous cycle. The first inventory is the baseline.
+func (c *SocketCollector) trackListeners(listeners []models.ListeningPort) {
+	current := make(map[string]bool, len(listeners))
+	for _, l := range listeners {
+		key := fmt.Sprintf("%s/%s:%d", l.Protocol, l.Address, l.Port)
+		current[key] = true
+
+		if c.listeners == nil || c.listeners[key] || c.events == nil {
+			continue
+		}
+
+		c.events.Push(models.Event{
+			Type:    "listener_opened",
+			Source:  "network",
+			Subject: key,
+			Message: fmt.Sprintf("New %s listener on %s:%d (%s)", l.Protocol, l.Address, l.Port, l.Process),
+			Attributes: map[string]string{
+				"pid":     fmt.Sprint(l.PID),
+				"process": l.Process,
+			},
+			Timestamp: time.Now(),
+		})
+	}
+	c.listeners = current
+}
+
+func (c *SocketCollector) addProtoRates(ctx context.Context, metric *models.SocketMetric) error {
+	stats, err := net.ProtoCountersWithContext(ctx, []string{"tcp", "udp"})
+	if err != nil {
+		return err
+	}
+
+	counters := &snmpCounters{at: time.Now()}
+	for _, stat := range stats {
+		switch stat.Protocol {
+		case "tcp":
+			counters.tcp = stat.Stats
+		case "udp":
+			counters.udp = stat.Stats
+		}
+	}
+
+	if prev := c.last; prev != nil {
+		elapsed := counters.at.Sub(prev.at).Seconds()
+		tcpRate := func(key string) float64 { return counter.Rate(prev.tcp[key], counters.tcp[key], elapsed) }
+		udpRate := func(key string) float64 { return counter.Rate(prev.udp[key], counters.udp[key], elapsed) }
+
+		metric.TCPOutSegsPerSec = tcpRate("OutSegs")
+		metric.TCPRetransSegsPerSec = tcpRate("RetransSegs")
+		metric.TCPInErrorsPerSec = tcpRate("InErrs")
+		metric.TCPResetsPerSec = tcpRate("OutRsts")
+		if metric.TCPOutSegsPerSec > 0 {
+			metric.TCPRetransPercent = metric.TCPRetransSegsPerSec / metric.TCPOutSegsPerSec * 100
+		}
+
+		metric.UDPInDatagramsPerSec = udpRate("InDatagrams")
+		metric.UDPOutDatagramsPerSec = udpRate("OutDatagrams")
+		metric.UDPInErrorsPerSec = udpRate("InErrors")
+		metric.UDPNoPortsPerSec = udpRate("NoPorts")
+	}
+	c.last = counters
+
+	return nil
+}
@@ -0,0 +1,127 @@
+// internal/runtime/runtime.go
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/containerd"
+	"pulse_agent/internal/docker"
+	"pulse_agent/internal/kubelet"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+)
+
+// Runtime is a container runtime the collector reports containers from.
+// Docker and Podman are both served by *docker.Client, which also
+// provides events, logs, disk usage and container actions.
+type Runtime interface {
+	Name() string
+	IsAvailable() bool
+	GetContainerStats(ctx context.Context) ([]models.ContainerMetric, error)
+	Close() error
+}
+
+var (
+	dockerSockets     = []string{"/var/run/docker.sock"}
+	containerdSockets = []string{"/run/containerd/containerd.sock", "/run/k3s/containerd/containerd.sock"}
+)
+
+// Detect creates the configured runtime. In auto mode it picks the
+// kubelet when running in a Kubernetes pod, otherwise it probes the
+// Docker, Podman and containerd sockets in that order and falls back to
+// Docker, which keeps retrying in the background. It returns nil for
+// "none".
+func Detect(cfg *config.Config) (Runtime, error) {
+	name := cfg.Runtime.Name
+	if name == config.RuntimeAuto {
+		name = detect(cfg)
+		logger.Info("Detected container runtime: %s", name)
+	}
+
+	var (
+		rt  Runtime
+		err error
+	)
+
+	// Assigned through typed variables so that a failed constructor
+	// yields a nil Runtime rather than a nil pointer in an interface
+	switch name {
+	case config.RuntimeDocker:
+		var c *docker.Client
+		if c, err = docker.NewClient(cfg); err == nil {
+			rt = c
+		}
+	case config.RuntimePodman:
+		socket := cfg.Runtime.PodmanSocket
+		if socket == "" {
+			socket = firstSocket(podmanSockets())
+		}
+		if socket == "" {
+			return nil, errors.New("no Podman socket found, set AGENT_PODMAN_SOCKET")
+		}
+		var c *docker.Client
+		if c, err = docker.NewPodmanClient(cfg, socket); err == nil {
+			rt = c
+		}
+	case config.RuntimeContainerd:
+		address := cfg.Runtime.ContainerdAddress
+		if address == "" {
+			address = firstSocket(containerdSockets)
+		}
+		if address == "" {
+			return nil, errors.New("no containerd socket found, set AGENT_CONTAINERD_ADDRESS")
+		}
+		var c *containerd.Client
+		if c, err = containerd.NewClient(cfg, address); err == nil {
+			rt = c
+		}
+	case config.RuntimeKubernetes:
+		var c *kubelet.Client
+		if c, err = kubelet.NewClient(cfg); err == nil {
+			rt = c
+		}
+	}
+
+	return rt, err
+}
+
+func detect(cfg *config.Config) string {
+	// Set in every pod; the agent runs as a DaemonSet
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return config.RuntimeKubernetes
+	}
+	if os.Getenv("DOCKER_HOST") != "" || firstSocket(dockerSockets) != "" {
+		return config.RuntimeDocker
+	}
+	if cfg.Runtime.PodmanSocket != "" || firstSocket(podmanSockets()) != "" {
+		return config.RuntimePodman
+	}
+	if cfg.Runtime.ContainerdAddress != "" || firstSocket(containerdSockets) != "" {
+		return config.RuntimeContainerd
+	}
+	return config.RuntimeDocker
+}
+
+// podmanSockets lists the rootful socket, then the rootless one of the
+// user the agent runs as
+func podmanSockets() []string {
+	sockets := []string{"/run/podman/podman.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "podman", "podman.sock"))
+	}
+	return append(sockets, fmt.Sprintf("/run/user/%d/podman/podman.sock", os.Getuid()))
+}
+
+func firstSocket(paths []string) string {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return path
+		}
+	}
+	return ""
+}
@@ -10,6 +10,7 @@ import (
 	"pulse_agent/internal/agent"
 	"pulse_agent/internal/collector"
 	"pulse_agent/internal/config"
+	"pulse_agent/internal/runtime"
 	"pulse_agent/internal/sender"
 	"pulse_agent/pkg/logger"
 )
@@ -21,13 +22,22 @@ type Scheduler struct {
 	stopChan  chan struct{}
 }
 
-func New(cfg *config.Config) *Scheduler {
-	return &Scheduler{
+func New(cfg *config.Config, rt runtime.Runtime) *Scheduler {
+	s := &Scheduler{
 		cfg:       cfg,
-		collector: collector.New(cfg),
+		collector: collector.New(cfg, rt),
 		sender:    sender.New(cfg),
 		stopChan:  make(chan struct{}),
 	}
+
+	s.collector.StartLogForwarding(s.sender.SendLogs)
+	return s
+}
+
+// LogsDone is closed once forwarded logs are flushed after the runtime
+// was closed
+func (s *Scheduler) LogsDone() <-chan struct{} {
+	return s.collector.LogsDone()
 }
 
 func (s *Scheduler) Start() {
@@ -79,14 +89,21 @@ func (s *Scheduler) runCollection() {
 				err = s.sender.Send(ctx, payload)
 				if err != nil {
 					logger.Error("Send failed after re-registration: %v", err)
+					s.collector.RequeueEvents(payload.Events)
+					s.collector.RequeueDiskUsage(payload.DockerDisk)
 					return
 				}
 			} else {
 				logger.Error("Re-registration failed")
+				s.collector.RequeueEvents(payload.Events)
+				s.collector.RequeueDiskUsage(payload.DockerDisk)
 				return
 			}
 		} else {
 			logger.Error("Send failed: %v", err)
+			// Keep events and disk usage until the backend is reachable again
+			s.collector.RequeueEvents(payload.Events)
+			s.collector.RequeueDiskUsage(payload.DockerDisk)
 			return
 		}
 	}
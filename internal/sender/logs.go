@@ -0,0 +1,16 @@
+package sender
+
+import (
+	"context"
+
+	"pulse_agent/internal/models"
+)
+
+// SendLogs ships a batch of container log lines
+func (s *Sender) SendLogs(ctx context.Context, entries []models.LogEntry, dropped int) error {
+	return s.post(ctx, "/api/v1/agent/logs", models.LogBatch{
+		ServerID: s.cfg.ServerID,
+		Entries:  entries,
+		Dropped:  dropped,
+	})
+}
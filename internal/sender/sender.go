@@ -67,12 +67,17 @@ func VerifyServerID(ctx context.Context, cfg *config.Config, serverID string) er
 }
 
 func (s *Sender) Send(ctx context.Context, payload *models.Payload) error {
-	data, err := json.Marshal(payload)
+	return s.post(ctx, "/api/v1/agent/storeMetric", payload)
+}
+
+// post sends v as JSON to the backend and maps error responses
+func (s *Sender) post(ctx context.Context, path string, v interface{}) error {
+	data, err := json.Marshal(v)
 	if err != nil {
 		return fmt.Errorf("marshal payload failed: %w", err)
 	}
 
-	endpoint := fmt.Sprintf("%s/api/v1/agent/storeMetric", s.cfg.BackendURL)
+	endpoint := s.cfg.BackendURL + path
 
 	req, err := http.NewRequestWithContext(
 		ctx,
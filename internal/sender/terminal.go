@@ -0,0 +1,13 @@
+package sender
+
+import (
+	"context"
+
+	"pulse_agent/internal/models"
+)
+
+// SendRecording uploads a finished terminal session recording
+func (s *Sender) SendRecording(ctx context.Context, rec models.TerminalRecording) error {
+	rec.ServerID = s.cfg.ServerID
+	return s.post(ctx, "/api/v1/agent/terminal/recordings", rec)
+}
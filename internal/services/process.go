@@ -0,0 +1,117 @@
+// internal/services/process.go
+package services
+
+import (
+	"context"
+	"time"
+
+	"pulse_agent/internal/models"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// processTable caches process handles between cycles so that
+// CPU percentages are computed over the collection interval.
+type processTable struct {
+	procs map[int32]*process.Process
+}
+
+func newProcessTable() *processTable {
+	return &processTable{procs: make(map[int32]*process.Process)}
+}
+
+func (t *processTable) refresh(ctx context.Context) error {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	alive := make(map[int32]*process.Process, len(pids))
+	for _, pid := range pids {
+		if p, ok := t.procs[pid]; ok {
+			alive[pid] = p
+			continue
+		}
+		p, err := process.NewProcessWithContext(ctx, pid)
+		if err != nil {
+			continue
+		}
+		alive[pid] = p
+	}
+
+	t.procs = alive
+	return nil
+}
+
+func (t *processTable) collect(ctx context.Context, tgt target) models.ServiceMetric {
+	metric := models.ServiceMetric{Name: tgt.name, Source: SourceProcess, State: "stopped"}
+
+	var (
+		pids   []int32
+		oldest int64
+	)
+
+	for pid, p := range t.procs {
+		if !t.matches(ctx, p, tgt) {
+			continue
+		}
+		pids = append(pids, pid)
+
+		created, err := p.CreateTimeWithContext(ctx)
+		if err != nil {
+			continue
+		}
+		// The longest-running match is reported as the main process
+		if oldest == 0 || created < oldest {
+			oldest = created
+			metric.PID = pid
+		}
+	}
+
+	if len(pids) == 0 {
+		return metric
+	}
+
+	metric.Running = true
+	metric.State = "running"
+	metric.ProcessCount = len(pids)
+	if oldest > 0 {
+		metric.UptimeSeconds = uint64(time.Since(time.UnixMilli(oldest)).Seconds())
+	}
+	metric.CPUPercent, metric.MemoryUsageMB = t.usage(ctx, pids)
+
+	return metric
+}
+
+func (t *processTable) matches(ctx context.Context, p *process.Process, tgt target) bool {
+	if tgt.kind == kindRegex {
+		cmdline, err := p.CmdlineWithContext(ctx)
+		return err == nil && tgt.re.MatchString(cmdline)
+	}
+
+	name, err := p.NameWithContext(ctx)
+	return err == nil && name == tgt.value
+}
+
+// usage sums CPU percent and resident memory over the given processes
+func (t *processTable) usage(ctx context.Context, pids []int32) (float64, int) {
+	var (
+		cpuPercent float64
+		rss        uint64
+	)
+
+	for _, pid := range pids {
+		p, ok := t.procs[pid]
+		if !ok {
+			continue
+		}
+		if percent, err := p.PercentWithContext(ctx, 0); err == nil {
+			cpuPercent += percent
+		}
+		if mem, err := p.MemoryInfoWithContext(ctx); err == nil {
+			rss += mem.RSS
+		}
+	}
+
+	return cpuPercent, int(rss / 1024 / 1024)
+}
@@ -0,0 +1,226 @@
+// internal/services/services.go
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/events"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+)
+
+const (
+	SourceSystemd = "systemd"
+	SourceProcess = "process"
+)
+
+type targetKind int
+
+const (
+	kindAuto    targetKind = iota // systemd unit on systemd hosts, process name elsewhere
+	kindUnit                      // "unit:nginx.service"
+	kindProcess                   // "process:redis-server"
+	kindRegex                     // "regex:java.*kafka" (matched against the command line)
+)
+
+type target struct {
+	name  string // spec as configured, used as the service name in payloads
+	kind  targetKind
+	value string
+	re    *regexp.Regexp
+}
+
+type serviceState struct {
+	running  bool
+	pid      int32
+	restarts int
+}
+
+// Collector reports the health of the services listed in AGENT_WATCH_SERVICES
+type Collector struct {
+	targets []target
+	events  *events.Buffer
+	systemd *systemdClient
+	procs   *processTable
+	last    map[string]*serviceState
+}
+
+func New(cfg *config.Config, eventBuf *events.Buffer) *Collector {
+	c := &Collector{
+		events: eventBuf,
+		procs:  newProcessTable(),
+		last:   make(map[string]*serviceState),
+	}
+
+	for _, spec := range cfg.WatchServices {
+		t, err := parseTarget(spec)
+		if err != nil {
+			logger.Warn("Ignoring watched service %q: %v", spec, err)
+			continue
+		}
+		c.targets = append(c.targets, t)
+	}
+
+	if len(c.targets) > 0 && isSystemdHost() {
+		systemd, err := newSystemdClient(context.Background())
+		if err != nil {
+			logger.Warn("systemd D-Bus not available, falling back to process matching: %v", err)
+		} else {
+			c.systemd = systemd
+		}
+	}
+
+	return c
+}
+
+func parseTarget(spec string) (target, error) {
+	t := target{name: spec, kind: kindAuto, value: spec}
+
+	if prefix, value, ok := strings.Cut(spec, ":"); ok {
+		switch prefix {
+		case "unit":
+			t.kind = kindUnit
+		case "process":
+			t.kind = kindProcess
+		case "regex":
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return t, fmt.Errorf("invalid regex: %w", err)
+			}
+			t.kind = kindRegex
+			t.re = re
+		default:
+			return t, fmt.Errorf("unknown prefix %q", prefix)
+		}
+		t.value = value
+	}
+
+	if t.value == "" {
+		return t, fmt.Errorf("empty service name")
+	}
+	return t, nil
+}
+
+func (c *Collector) Enabled() bool {
+	return len(c.targets) > 0
+}
+
+func (c *Collector) Collect(ctx context.Context) []models.ServiceMetric {
+	if !c.Enabled() {
+		return nil
+	}
+
+	if err := c.procs.refresh(ctx); err != nil {
+		logger.Warn("Failed to list processes: %v", err)
+	}
+
+	metrics := make([]models.ServiceMetric, 0, len(c.targets))
+	for _, t := range c.targets {
+		metric := c.collectTarget(ctx, t)
+		c.trackState(&metric)
+		metrics = append(metrics, metric)
+	}
+
+	return metrics
+}
+
+func (c *Collector) collectTarget(ctx context.Context, t target) models.ServiceMetric {
+	useSystemd := t.kind == kindUnit || (t.kind == kindAuto && c.systemd != nil)
+
+	if useSystemd && c.systemd != nil {
+		metric, err := c.systemd.collect(ctx, t.name, unitName(t.value), c.procs)
+		switch {
+		case err == nil:
+			return metric
+		case errors.Is(err, errUnitNotFound):
+			// A bare name that is not a unit may still be a process
+			if t.kind == kindUnit {
+				return metric
+			}
+		default:
+			logger.Warn("Failed to query systemd unit %s: %v", t.value, err)
+		}
+	}
+
+	if t.kind == kindUnit {
+		// Explicit units cannot be matched against processes
+		return models.ServiceMetric{Name: t.name, Source: SourceSystemd, State: "unknown"}
+	}
+
+	return c.procs.collect(ctx, t)
+}
+
+// trackState detects restarts and up/down transitions between cycles
+func (c *Collector) trackState(metric *models.ServiceMetric) {
+	prev, seen := c.last[metric.Name]
+	if !seen {
+		prev = &serviceState{running: true}
+		c.last[metric.Name] = prev
+	}
+
+	// systemd counts restarts itself; for processes a new PID is a restart
+	if metric.Source == SourceProcess {
+		if seen && metric.Running && (!prev.running || prev.pid != metric.PID) {
+			prev.restarts++
+		}
+		metric.RestartCount = prev.restarts
+	}
+
+	if prev.running && !metric.Running {
+		c.emit(metric, "service_down", fmt.Sprintf("Service %s is %s", metric.Name, metric.State))
+	} else if seen && !prev.running && metric.Running {
+		c.emit(metric, "service_up", fmt.Sprintf("Service %s is running (pid %d)", metric.Name, metric.PID))
+	}
+
+	prev.running = metric.Running
+	prev.pid = metric.PID
+}
+
+func (c *Collector) emit(metric *models.ServiceMetric, eventType, message string) {
+	logger.Warn("%s", message)
+
+	if c.events == nil {
+		return
+	}
+
+	attributes := map[string]string{"state": metric.State}
+	if metric.SubState != "" {
+		attributes["sub_state"] = metric.SubState
+	}
+
+	c.events.Push(models.Event{
+		Type:       eventType,
+		Source:     metric.Source,
+		Subject:    metric.Name,
+		Message:    message,
+		Attributes: attributes,
+		Timestamp:  time.Now(),
+	})
+}
+
+func (c *Collector) Close() {
+	if c.systemd != nil {
+		c.systemd.close()
+	}
+}
+
+var unitSuffixes = []string{
+	".service", ".socket", ".timer", ".mount", ".automount",
+	".path", ".target", ".scope", ".slice", ".swap", ".device",
+}
+
+// unitName appends ".service" when no unit type suffix is given
+func unitName(name string) string {
+	for _, suffix := range unitSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return name
+		}
+	}
+	return name + ".service"
+}
@@ -0,0 +1,122 @@
+// internal/services/systemd.go
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"pulse_agent/internal/models"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+)
+
+// errUnitNotFound is returned, with a "not-found" metric, for units
+// systemd does not know
+var errUnitNotFound = errors.New("unit not found")
+
+type cpuSample struct {
+	usageNSec uint64
+	at        time.Time
+}
+
+type systemdClient struct {
+	conn *dbus.Conn
+	cpu  map[string]cpuSample
+}
+
+// isSystemdHost mirrors sd_booted(3)
+func isSystemdHost() bool {
+	_, err := os.Stat("/run/systemd/system")
+	return err == nil
+}
+
+func newSystemdClient(ctx context.Context) (*systemdClient, error) {
+	conn, err := dbus.NewSystemConnectionContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &systemdClient{conn: conn, cpu: make(map[string]cpuSample)}, nil
+}
+
+func (s *systemdClient) collect(ctx context.Context, name, unit string, procs *processTable) (models.ServiceMetric, error) {
+	metric := models.ServiceMetric{Name: name, Source: SourceSystemd}
+
+	props, err := s.conn.GetUnitPropertiesContext(ctx, unit)
+	if err != nil {
+		return metric, err
+	}
+
+	metric.State, _ = props["ActiveState"].(string)
+	metric.SubState, _ = props["SubState"].(string)
+	if loadState, _ := props["LoadState"].(string); loadState == "not-found" {
+		metric.State = loadState
+		return metric, errUnitNotFound
+	}
+	metric.Running = metric.State == "active" || metric.State == "reloading"
+
+	if metric.Running {
+		if since, ok := props["ActiveEnterTimestamp"].(uint64); ok && since > 0 {
+			metric.UptimeSeconds = uint64(time.Since(time.UnixMicro(int64(since))).Seconds())
+		}
+	}
+
+	// MainPID, NRestarts and resource accounting live on the Service interface
+	if !strings.HasSuffix(unit, ".service") {
+		return metric, nil
+	}
+
+	svc, err := s.conn.GetUnitTypePropertiesContext(ctx, unit, "Service")
+	if err != nil {
+		return metric, err
+	}
+
+	if pid, ok := svc["MainPID"].(uint32); ok {
+		metric.PID = int32(pid)
+	}
+	if restarts, ok := svc["NRestarts"].(uint32); ok {
+		metric.RestartCount = int(restarts)
+	}
+	if metric.PID > 0 {
+		metric.ProcessCount = 1
+	}
+
+	// Prefer cgroup accounting; systemd reports MaxUint64 when it is disabled
+	cpuNSec, cpuOK := svc["CPUUsageNSec"].(uint64)
+	memBytes, memOK := svc["MemoryCurrent"].(uint64)
+	cpuOK = cpuOK && cpuNSec != math.MaxUint64
+	memOK = memOK && memBytes != math.MaxUint64
+
+	if cpuOK {
+		now := time.Now()
+		if prev, ok := s.cpu[unit]; ok && cpuNSec >= prev.usageNSec {
+			elapsed := now.Sub(prev.at).Nanoseconds()
+			if elapsed > 0 {
+				metric.CPUPercent = float64(cpuNSec-prev.usageNSec) / float64(elapsed) * 100
+			}
+		}
+		s.cpu[unit] = cpuSample{usageNSec: cpuNSec, at: now}
+	}
+	if memOK {
+		metric.MemoryUsageMB = int(memBytes / 1024 / 1024)
+	}
+
+	if (!cpuOK || !memOK) && metric.PID > 0 {
+		cpuPercent, memMB := procs.usage(ctx, []int32{metric.PID})
+		if !cpuOK {
+			metric.CPUPercent = cpuPercent
+		}
+		if !memOK {
+			metric.MemoryUsageMB = memMB
+		}
+	}
+
+	return metric, nil
+}
+
+func (s *systemdClient) close() {
+	s.conn.Close()
+}
@@ -0,0 +1,126 @@
+// internal/system/kernel.go
+package system
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"pulse_agent/internal/counter"
+	"pulse_agent/internal/models"
+)
+
+type kernelCounters struct {
+	contextSwitches uint64
+	interrupts      uint64
+	forks           uint64
+	at              time.Time
+}
+
+// KernelCollector reads kernel counters that explain a "slow" host
+// when CPU usage looks fine: scheduler activity, file handles, conntrack.
+type KernelCollector struct {
+	last *kernelCounters
+}
+
+func NewKernelCollector() *KernelCollector {
+	return &KernelCollector{}
+}
+
+// GetKernelStats returns nil without error on hosts without procfs
+func (c *KernelCollector) GetKernelStats(ctx context.Context) (*models.KernelMetric, error) {
+	counters, metric, err := readProcStat()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if c.last != nil {
+		elapsed := counters.at.Sub(c.last.at).Seconds()
+		metric.ContextSwitchesPerSec = counter.Rate(c.last.contextSwitches, counters.contextSwitches, elapsed)
+		metric.InterruptsPerSec = counter.Rate(c.last.interrupts, counters.interrupts, elapsed)
+		metric.ForksPerSec = counter.Rate(c.last.forks, counters.forks, elapsed)
+	}
+	c.last = counters
+
+	// file-nr: allocated, unused (always 0 since 2.6), max
+	if data, err := os.ReadFile(HostProc("sys", "fs", "file-nr")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 3 {
+			allocated, _ := strconv.ParseUint(fields[0], 10, 64)
+			unused, _ := strconv.ParseUint(fields[1], 10, 64)
+			metric.OpenFilesMax, _ = strconv.ParseUint(fields[2], 10, 64)
+			metric.OpenFiles = allocated - unused
+			metric.OpenFilesPercent = percent(metric.OpenFiles, metric.OpenFilesMax)
+		}
+	}
+
+	if entropy, err := readUintFile(HostProc("sys", "kernel", "random", "entropy_avail")); err == nil {
+		metric.EntropyAvailable = int(entropy)
+	}
+
+	// Only present when the nf_conntrack module is loaded
+	if count, err := readUintFile(HostProc("sys", "net", "netfilter", "nf_conntrack_count")); err == nil {
+		metric.ConntrackCount = count
+		metric.ConntrackMax, _ = readUintFile(HostProc("sys", "net", "netfilter", "nf_conntrack_max"))
+		metric.ConntrackPercent = percent(metric.ConntrackCount, metric.ConntrackMax)
+	}
+
+	return metric, nil
+}
+
+func readProcStat() (*kernelCounters, *models.KernelMetric, error) {
+	f, err := os.Open(HostProc("stat"))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	counters := &kernelCounters{at: time.Now()}
+	metric := &models.KernelMetric{}
+
+	scanner := bufio.NewScanner(f)
+	// The intr line lists every interrupt and can be very long
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		switch fields[0] {
+		case "ctxt":
+			counters.contextSwitches = value
+		case "intr":
+			counters.interrupts = value
+		case "processes":
+			counters.forks = value
+		case "procs_running":
+			metric.ProcsRunning = int(value)
+		case "procs_blocked":
+			metric.ProcsBlocked = int(value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return counters, metric, nil
+}
+
+func percent(used, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(used) / float64(total) * 100
+}
@@ -0,0 +1,79 @@
+// internal/system/pressure.go
+package system
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"pulse_agent/internal/models"
+)
+
+// readPressure returns nil when PSI is unsupported (non-Linux or kernel < 4.20)
+func readPressure() *models.PressureMetric {
+	pressure := &models.PressureMetric{
+		CPU:    readPressureFile(HostProc("pressure", "cpu")),
+		Memory: readPressureFile(HostProc("pressure", "memory")),
+		IO:     readPressureFile(HostProc("pressure", "io")),
+	}
+
+	if pressure.CPU == nil && pressure.Memory == nil && pressure.IO == nil {
+		return nil
+	}
+	return pressure
+}
+
+// readPressureFile parses lines like:
+//
+//	some avg10=0.00 avg60=0.00 avg300=0.00 total=0
+//	full avg10=0.00 avg60=0.00 avg300=0.00 total=0
+func readPressureFile(path string) *models.PressureStat {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	stat := &models.PressureStat{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		var avg10, avg60, avg300 *float64
+		var total *uint64
+		switch fields[0] {
+		case "some":
+			avg10, avg60, avg300, total = &stat.SomeAvg10, &stat.SomeAvg60, &stat.SomeAvg300, &stat.SomeTotal
+		case "full":
+			avg10, avg60, avg300, total = &stat.FullAvg10, &stat.FullAvg60, &stat.FullAvg300, &stat.FullTotal
+		default:
+			continue
+		}
+
+		for _, field := range fields[1:] {
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				continue
+			}
+			switch key {
+			case "avg10":
+				*avg10, _ = strconv.ParseFloat(value, 64)
+			case "avg60":
+				*avg60, _ = strconv.ParseFloat(value, 64)
+			case "avg300":
+				*avg300, _ = strconv.ParseFloat(value, 64)
+			case "total":
+				*total, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+	}
+
+	if scanner.Err() != nil {
+		return nil
+	}
+	return stat
+}
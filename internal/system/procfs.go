@@ -0,0 +1,37 @@
+// internal/system/procfs.go
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// HostProc resolves a path under /proc, honouring HOST_PROC like gopsutil
+// does when the agent runs in a container with the host /proc mounted.
+func HostProc(elem ...string) string {
+	root := os.Getenv("HOST_PROC")
+	if root == "" {
+		root = "/proc"
+	}
+	return filepath.Join(append([]string{root}, elem...)...)
+}
+
+// hostSys resolves a path under /sys, honouring HOST_SYS like gopsutil
+func hostSys(elem ...string) string {
+	root := os.Getenv("HOST_SYS")
+	if root == "" {
+		root = "/sys"
+	}
+	return filepath.Join(append([]string{root}, elem...)...)
+}
+
+// readUintFile reads a file containing a single unsigned integer
+func readUintFile(path string) (uint64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
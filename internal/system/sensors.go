@@ -0,0 +1,125 @@
+// internal/system/sensors.go
+package system
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"pulse_agent/internal/models"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// Driver name prefixes as exposed in /sys/class/hwmon/*/name
+var sensorCategories = []struct {
+	prefix   string
+	category string
+}{
+	{"coretemp", "cpu"},
+	{"k10temp", "cpu"},
+	{"zenpower", "cpu"},
+	{"cpu_thermal", "cpu"},
+	{"x86_pkg_temp", "cpu"},
+	{"nvme", "nvme"},
+	{"drivetemp", "disk"},
+	{"amdgpu", "gpu"},
+	{"nouveau", "gpu"},
+}
+
+// SensorCollector reads temperatures (hwmon, falling back to thermal
+// zones) and fan speeds.
+type SensorCollector struct{}
+
+func NewSensorCollector() *SensorCollector {
+	return &SensorCollector{}
+}
+
+func (c *SensorCollector) GetSensorStats(ctx context.Context) ([]models.SensorMetric, error) {
+	// gopsutil returns partial results together with *host.Warnings
+	temps, err := host.SensorsTemperaturesWithContext(ctx)
+	if err != nil && len(temps) == 0 {
+		if _, ok := err.(*host.Warnings); !ok {
+			return nil, err
+		}
+	}
+
+	sensors := make([]models.SensorMetric, 0, len(temps))
+	for _, t := range temps {
+		sensors = append(sensors, models.SensorMetric{
+			Name:     t.SensorKey,
+			Kind:     "temperature",
+			Category: sensorCategory(t.SensorKey),
+			Value:    t.Temperature,
+			Unit:     "celsius",
+			High:     t.High,
+			Critical: t.Critical,
+		})
+	}
+
+	sensors = append(sensors, readFans()...)
+
+	sort.SliceStable(sensors, func(i, j int) bool {
+		return sensors[i].Name < sensors[j].Name
+	})
+
+	return sensors, nil
+}
+
+// readFans reads fan*_input from hwmon; gopsutil has no fan support
+func readFans() []models.SensorMetric {
+	files, _ := filepath.Glob(hostSys("class", "hwmon", "hwmon*", "fan*_input"))
+
+	fans := make([]models.SensorMetric, 0, len(files))
+	for _, file := range files {
+		rpm, err := readUintFile(file)
+		if err != nil {
+			continue
+		}
+
+		dir := filepath.Dir(file)
+		base := strings.TrimSuffix(filepath.Base(file), "_input") // fan1
+
+		name := base
+		if chip, err := os.ReadFile(filepath.Join(dir, "name")); err == nil {
+			name = strings.TrimSpace(string(chip)) + "_" + base
+		}
+		if label, err := os.ReadFile(filepath.Join(dir, base+"_label")); err == nil && len(label) > 0 {
+			name = strings.TrimSuffix(name, base) + sensorLabel(string(label))
+		}
+
+		fan := models.SensorMetric{
+			Name:     name,
+			Kind:     "fan",
+			Category: sensorCategory(name),
+			Value:    float64(rpm),
+			Unit:     "rpm",
+		}
+		if min, err := readUintFile(filepath.Join(dir, base+"_min")); err == nil {
+			fan.Min = float64(min)
+		}
+		if max, err := readUintFile(filepath.Join(dir, base+"_max")); err == nil {
+			fan.High = float64(max)
+		}
+
+		fans = append(fans, fan)
+	}
+
+	return fans
+}
+
+func sensorCategory(name string) string {
+	for _, c := range sensorCategories {
+		if strings.HasPrefix(name, c.prefix) {
+			return c.category
+		}
+	}
+	return "other"
+}
+
+// sensorLabel formats "CPU Fan" as "cpu_fan", matching gopsutil's keys
+func sensorLabel(label string) string {
+	return strings.Join(strings.Fields(strings.ToLower(label)), "_")
+}
@@ -4,7 +4,9 @@ package system
 import (
 	"context"
 	"runtime"
+	"time"
 
+	"pulse_agent/internal/counter"
 	"pulse_agent/internal/models"
 
 	"github.com/shirou/gopsutil/v3/cpu"
@@ -13,7 +15,12 @@ import (
 	"github.com/shirou/gopsutil/v3/mem"
 )
 
-type Collector struct{}
+type Collector struct {
+	// Previous swap counters, used to turn them into rates
+	lastSwapIn  uint64
+	lastSwapOut uint64
+	lastSwapAt  time.Time
+}
 
 func NewCollector() *Collector {
 	return &Collector{}
@@ -50,8 +57,32 @@ func (c *Collector) GetSystemStats(ctx context.Context) (*models.SystemMetric, e
 		metric.MemoryTotalMB = int(memInfo.Total / 1024 / 1024)
 		metric.MemoryUsedMB = int(memInfo.Used / 1024 / 1024)
 		metric.MemoryPercent = memInfo.UsedPercent
+		metric.MemoryAvailableMB = int(memInfo.Available / 1024 / 1024)
+		metric.MemoryCachedMB = int(memInfo.Cached / 1024 / 1024)
+		metric.MemoryBuffersMB = int(memInfo.Buffers / 1024 / 1024)
+	}
+
+	// Swap stats (Sin/Sout are cumulative bytes swapped in/out)
+	swapInfo, err := mem.SwapMemoryWithContext(ctx)
+	if err == nil {
+		metric.SwapTotalMB = int(swapInfo.Total / 1024 / 1024)
+		metric.SwapUsedMB = int(swapInfo.Used / 1024 / 1024)
+		metric.SwapPercent = swapInfo.UsedPercent
+
+		now := time.Now()
+		if !c.lastSwapAt.IsZero() {
+			elapsed := now.Sub(c.lastSwapAt).Seconds()
+			metric.SwapInBytesPerSec = counter.Rate(c.lastSwapIn, swapInfo.Sin, elapsed)
+			metric.SwapOutBytesPerSec = counter.Rate(c.lastSwapOut, swapInfo.Sout, elapsed)
+		}
+		c.lastSwapIn = swapInfo.Sin
+		c.lastSwapOut = swapInfo.Sout
+		c.lastSwapAt = now
 	}
 
+	// Pressure stall information (Linux only)
+	metric.Pressure = readPressure()
+
 	// Disk stats
 	diskInfo, err := disk.UsageWithContext(ctx, "/")
 	if err == nil {
@@ -0,0 +1,120 @@
+package terminal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"pulse_agent/internal/config"
+)
+
+// ErrCommandDenied is returned for commands outside the allowlist
+var ErrCommandDenied = errors.New("not allowed by agent policy")
+
+// CommandResult is the outcome of a command run by RunCommand
+type CommandResult struct {
+	ExitStatus
+	// stdout and stderr interleaved, cut at CommandMaxOutput bytes
+	Output    string
+	Truncated bool
+}
+
+// RunCommand runs a command allowed by cfg.Commands directly, without a
+// shell or terminal, as the terminal user with the sanitized environment.
+// It is killed with its children after cfg.CommandTimeout.
+func RunCommand(ctx context.Context, cfg config.TerminalConfig, name string, args []string) (CommandResult, error) {
+	// Exit code -1 until it ran
+	notRun := CommandResult{ExitStatus: ExitStatus{Code: -1}}
+
+	if cfg.Mode == config.TerminalDisabled {
+		return notRun, fmt.Errorf("terminal: %w", ErrCommandDenied)
+	}
+	if !commandAllowed(cfg.Commands, name, args) {
+		return notRun, fmt.Errorf("%s: %w", name, ErrCommandDenied)
+	}
+
+	path, err := lookPath(name)
+	if err != nil {
+		return notRun, err
+	}
+
+	u, err := lookupShellUser(cfg.User, cfg.Group)
+	if err != nil {
+		return notRun, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.CommandTimeout)
+	defer cancel()
+
+	out := &limitedBuffer{max: cfg.CommandMaxOutput}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	asUser(cmd, cfg, u, "/bin/sh")
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	// Children that keep the output open do not hold up the result
+	cmd.WaitDelay = time.Second
+	cmd.Stdout, cmd.Stderr = out, out
+
+	err = cmd.Run()
+	result := CommandResult{
+		ExitStatus: exitStatus(cmd.ProcessState, err),
+		Output:     string(out.buf),
+		Truncated:  out.truncated,
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return result, fmt.Errorf("timed out after %s", cfg.CommandTimeout)
+	case err != nil && !errors.As(err, &exitErr):
+		return result, err
+	}
+	// A non-zero exit code is a result, not an error
+	return result, nil
+}
+
+func commandAllowed(allowed []config.TerminalCommand, name string, args []string) bool {
+	for _, cmd := range allowed {
+		if cmd.Allows(name, args) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookPath resolves name in the PATH shells get, not the agent's
+func lookPath(name string) (string, error) {
+	for _, dir := range filepath.SplitList(shellPath) {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("%s: command not found", name)
+}
+
+// limitedBuffer keeps the first max bytes written to it
+type limitedBuffer struct {
+	buf       []byte
+	max       int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - len(b.buf); room < len(p) {
+		b.buf = append(b.buf, p[:max(room, 0)]...)
+		b.truncated = true
+	} else {
+		b.buf = append(b.buf, p...)
+	}
+	// Keep the command running; the rest is discarded
+	return len(p), nil
+}
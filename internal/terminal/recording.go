@@ -0,0 +1,355 @@
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pulse_agent/internal/agent"
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/models"
+	"pulse_agent/pkg/logger"
+)
+
+const uploadTimeout = 60 * time.Second
+
+// A recording is written to name.cast.part and renamed to name.cast when
+// finished, so retention never sees an open one. Until it is uploaded,
+// name.cast.pending holds the metadata to upload it with.
+const (
+	partSuffix    = ".part"
+	pendingSuffix = ".pending"
+)
+
+// Characters kept from session IDs in file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// Uploader sends a finished recording to the backend
+type Uploader func(ctx context.Context, rec models.TerminalRecording) error
+
+// RecordingInfo describes the session a recording belongs to, as given
+// by the backend in terminal:open
+type RecordingInfo struct {
+	SessionID   string
+	RequestedBy string
+	Container   string
+	Rows, Cols  uint16
+}
+
+// Recordings writes terminal sessions as asciicast v2 files, applies the
+// retention limits and optionally uploads finished recordings
+type Recordings struct {
+	cfg    config.TerminalRecordingConfig
+	dir    string
+	upload Uploader // nil unless uploads are enabled
+
+	pruneMu  sync.Mutex
+	uploadMu sync.Mutex
+}
+
+// NewRecordings returns nil when recording is disabled. upload is only
+// used when the config enables uploads.
+func NewRecordings(cfg config.TerminalRecordingConfig, upload Uploader) *Recordings {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	r := &Recordings{cfg: cfg, dir: cfg.Dir}
+	if r.dir == "" {
+		r.dir = filepath.Join(agent.DataDir(), "recordings")
+	}
+	if cfg.Upload {
+		r.upload = upload
+	}
+
+	r.recover()
+	if r.upload != nil {
+		go r.uploadPending()
+	}
+	return r
+}
+
+// recover keeps recordings left open by an agent that did not shut down
+// cleanly; they are complete up to the last event written
+func (r *Recordings) recover() {
+	parts, _ := filepath.Glob(filepath.Join(r.dir, "*.cast"+partSuffix))
+	for _, part := range parts {
+		if err := os.Rename(part, strings.TrimSuffix(part, partSuffix)); err != nil {
+			logger.Warn("Failed to recover terminal recording %s: %v", filepath.Base(part), err)
+		}
+	}
+}
+
+// Record wraps t so that its output, resizes and, if configured, input
+// are written to a new recording, which is finished when t is closed.
+// A nil Recordings returns t unchanged.
+func (r *Recordings) Record(t Terminal, info RecordingInfo) (Terminal, error) {
+	if r == nil {
+		return t, nil
+	}
+
+	if err := os.MkdirAll(r.dir, 0700); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	name := fmt.Sprintf("%s-%s.cast", start.UTC().Format("20060102T150405Z"),
+		unsafeFileChars.ReplaceAllString(info.SessionID, "_"))
+	path := filepath.Join(r.dir, name)
+
+	f, err := os.OpenFile(path+partSuffix, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cols := info.Rows, info.Cols
+	if rows == 0 || cols == 0 {
+		rows, cols = 24, 80
+	}
+
+	title := "session " + info.SessionID
+	if info.RequestedBy != "" {
+		title += " by " + info.RequestedBy
+	}
+	if info.Container != "" {
+		title += " in " + info.Container
+	}
+
+	rec := &recording{
+		recordings: r,
+		info:       info,
+		path:       path,
+		file:       f,
+		start:      start,
+	}
+
+	header, _ := json.Marshal(map[string]interface{}{
+		"version":   2,
+		"width":     cols,
+		"height":    rows,
+		"timestamp": start.Unix(),
+		"title":     title,
+		"env":       map[string]string{"TERM": "xterm-256color"},
+	})
+	if _, err := f.Write(append(header, '\n')); err != nil {
+		f.Close()
+		os.Remove(path + partSuffix)
+		return nil, err
+	}
+
+	return &recordedTerminal{Terminal: t, rec: rec, input: r.cfg.Input}, nil
+}
+
+// finish is called once a recording is closed. Each finished recording
+// also retries the uploads that failed before.
+func (r *Recordings) finish(rec *recording, end time.Time) {
+	if r.upload != nil {
+		meta, _ := json.Marshal(models.TerminalRecording{
+			SessionID:   rec.info.SessionID,
+			RequestedBy: rec.info.RequestedBy,
+			Container:   rec.info.Container,
+			StartedAt:   rec.start,
+			EndedAt:     end,
+			Input:       r.cfg.Input,
+		})
+		if err := os.WriteFile(rec.path+pendingSuffix, meta, 0600); err != nil {
+			logger.Warn("Failed to queue terminal recording %s for upload: %v", filepath.Base(rec.path), err)
+		}
+		go r.uploadPending()
+	}
+	r.prune()
+}
+
+// uploadPending uploads every recording that is not uploaded yet, oldest
+// first, and stops at the first failure
+func (r *Recordings) uploadPending() {
+	r.uploadMu.Lock()
+	defer r.uploadMu.Unlock()
+
+	pending, _ := filepath.Glob(filepath.Join(r.dir, "*.cast"+pendingSuffix))
+	// File names start with the UTC start time
+	sort.Strings(pending)
+	for _, meta := range pending {
+		if err := r.send(strings.TrimSuffix(meta, pendingSuffix)); err != nil {
+			// Kept locally and retried after the next session, until
+			// retention removes it
+			logger.Warn("Failed to upload terminal recording %s: %v", filepath.Base(strings.TrimSuffix(meta, pendingSuffix)), err)
+			return
+		}
+	}
+}
+
+func (r *Recordings) send(path string) error {
+	meta, err := os.ReadFile(path + pendingSuffix)
+	if err != nil {
+		return err
+	}
+	var rec models.TerminalRecording
+	if err := json.Unmarshal(meta, &rec); err != nil {
+		// Not something a retry can fix
+		os.Remove(path + pendingSuffix)
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		// Already removed by retention
+		os.Remove(path + pendingSuffix)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rec.Cast = string(data)
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	if err := r.upload(ctx, rec); err != nil {
+		return err
+	}
+	if err := os.Remove(path + pendingSuffix); err != nil {
+		logger.Warn("Failed to mark terminal recording %s as uploaded: %v", filepath.Base(path), err)
+	}
+	logger.Info("Uploaded terminal recording of session %s", rec.SessionID)
+	return nil
+}
+
+// prune removes finished recordings older than MaxAge, then the oldest
+// ones until the directory fits in MaxSizeMB
+func (r *Recordings) prune() {
+	r.pruneMu.Lock()
+	defer r.pruneMu.Unlock()
+
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return
+	}
+
+	type castFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var (
+		files []castFile
+		total int64
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".cast") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, castFile{filepath.Join(r.dir, entry.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+
+	maxSize := int64(r.cfg.MaxSizeMB) * 1024 * 1024
+	for _, f := range files {
+		expired := r.cfg.MaxAge > 0 && time.Since(f.modTime) > r.cfg.MaxAge
+		oversize := maxSize > 0 && total > maxSize
+		if !expired && !oversize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil {
+			logger.Warn("Failed to remove terminal recording: %v", err)
+			continue
+		}
+		os.Remove(f.path + pendingSuffix)
+		total -= f.size
+	}
+}
+
+// recording is one open asciicast file
+type recording struct {
+	recordings *Recordings
+	info       RecordingInfo
+	path       string
+	start      time.Time
+
+	mu     sync.Mutex
+	file   *os.File // written unbuffered, see recover
+	closed bool
+}
+
+// event appends [elapsed seconds, code, data]; code is "o" for output,
+// "i" for input and "r" for a resize to "COLSxROWS"
+func (rec *recording) event(code string, data string) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed {
+		return
+	}
+
+	line, _ := json.Marshal([]interface{}{time.Since(rec.start).Seconds(), code, data})
+	if _, err := rec.file.Write(append(line, '\n')); err != nil {
+		logger.Warn("Failed to write terminal recording: %v", err)
+	}
+}
+
+func (rec *recording) close() {
+	rec.mu.Lock()
+	if rec.closed {
+		rec.mu.Unlock()
+		return
+	}
+	rec.closed = true
+	end := time.Now()
+
+	err := rec.file.Close()
+	rec.mu.Unlock()
+
+	if err != nil {
+		logger.Warn("Failed to finish terminal recording %s: %v", rec.path, err)
+	}
+	if err := os.Rename(rec.path+partSuffix, rec.path); err != nil {
+		logger.Warn("Failed to finish terminal recording %s: %v", rec.path, err)
+		return
+	}
+	rec.recordings.finish(rec, end)
+}
+
+// recordedTerminal records everything passing through a terminal
+type recordedTerminal struct {
+	Terminal
+	rec   *recording
+	input bool
+}
+
+func (t *recordedTerminal) Write(data []byte) error {
+	if t.input {
+		t.rec.event("i", string(data))
+	}
+	return t.Terminal.Write(data)
+}
+
+func (t *recordedTerminal) ReadLoop(fn func([]byte)) {
+	t.Terminal.ReadLoop(func(data []byte) {
+		t.rec.event("o", string(data))
+		fn(data)
+	})
+}
+
+func (t *recordedTerminal) Resize(rows, cols uint16) error {
+	t.rec.event("r", fmt.Sprintf("%dx%d", cols, rows))
+	return t.Terminal.Resize(rows, cols)
+}
+
+func (t *recordedTerminal) Close() {
+	t.Terminal.Close()
+	t.rec.close()
+}
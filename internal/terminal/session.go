@@ -1,34 +1,104 @@
 package terminal
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/creack/pty"
+	"golang.org/x/sys/unix"
+
+	"pulse_agent/internal/config"
+)
+
+const (
+	// Between the hangup on Close and killing what is left
+	killDelay = 5 * time.Second
+	// Background jobs can keep the terminal open after the shell exited
+	drainDelay = time.Second
 )
 
 type Session struct {
 	Cmd *exec.Cmd
 	Pty *os.File
+
+	done      chan struct{} // closed once the shell was reaped
+	status    ExitStatus
+	closePty  sync.Once
+	closeOnce sync.Once
 }
 
-func StartShell() (*Session, error) {
-	shell := "/bin/bash"
-	if _, err := os.Stat(shell); err != nil {
-		shell = "/bin/sh"
+// StartShell starts a login shell on the host in a new pseudo-terminal
+// of the given size, as the user in cfg with a sanitized environment
+func StartShell(cfg config.TerminalConfig, rows, cols uint16) (*Session, error) {
+	cmd, u, err := shellCommand(cfg)
+	if err != nil {
+		return nil, err
 	}
 
-	cmd := exec.Command(shell)
-	cmd.Env = os.Environ()
-	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
-
-	ptmx, err := pty.Start(cmd)
+	ptmx, tty, err := pty.Open()
 	if err != nil {
 		return nil, err
 	}
+	defer tty.Close()
+
+	if rows > 0 && cols > 0 {
+		_ = pty.Setsize(ptmx, &pty.Winsize{Rows: rows, Cols: cols})
+	}
+	// Like login(1), so the user can reopen its own terminal
+	if u.switchUser {
+		if err := tty.Chown(int(u.uid), int(u.gid)); err != nil {
+			_ = ptmx.Close()
+			return nil, err
+		}
+	}
 
-	return &Session{Cmd: cmd, Pty: ptmx}, nil
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = tty, tty, tty
+	if err := cmd.Start(); err != nil {
+		_ = ptmx.Close()
+		return nil, fmt.Errorf("start %s as %s: %w", cmd.Path, u.name, err)
+	}
+
+	s := &Session{Cmd: cmd, Pty: ptmx, done: make(chan struct{})}
+	go s.reap()
+	return s, nil
+}
+
+// reap waits for the shell so it does not linger as a zombie, whether or
+// not anyone calls Wait
+func (s *Session) reap() {
+	err := s.Cmd.Wait()
+	s.status = exitStatus(s.Cmd.ProcessState, err)
+	close(s.done)
+
+	time.AfterFunc(drainDelay, s.closeTerminal)
+}
+
+func exitStatus(state *os.ProcessState, err error) ExitStatus {
+	if state == nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return ExitStatus{Code: -1}
+		}
+		state = exitErr.ProcessState
+	}
+
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return ExitStatus{Code: -1, Signal: signalName(ws.Signal())}
+	}
+	return ExitStatus{Code: state.ExitCode()}
+}
+
+// signalName returns "SIGHUP" rather than "hangup"
+func signalName(sig syscall.Signal) string {
+	if name := unix.SignalName(sig); name != "" {
+		return name
+	}
+	return sig.String()
 }
 
 func (s *Session) Write(data []byte) error {
@@ -36,6 +106,15 @@ func (s *Session) Write(data []byte) error {
 	return err
 }
 
+func (s *Session) Resize(rows, cols uint16) error {
+	return pty.Setsize(s.Pty, &pty.Winsize{Rows: rows, Cols: cols})
+}
+
+func (s *Session) Wait() ExitStatus {
+	<-s.done
+	return s.status
+}
+
 func (s *Session) ReadLoop(fn func([]byte)) {
 	buf := make([]byte, 4096)
 	for {
@@ -47,11 +126,26 @@ func (s *Session) ReadLoop(fn func([]byte)) {
 	}
 }
 
+// Close hangs up the shell's process group like a closed terminal
+// would, and kills it if it is still there after killDelay
 func (s *Session) Close() {
-	if s.Pty != nil {
-		_ = s.Pty.Close()
-	}
-	if s.Cmd != nil && s.Cmd.Process != nil {
-		_ = s.Cmd.Process.Kill()
-	}
+	s.closeOnce.Do(func() {
+		s.closeTerminal()
+
+		pgid := s.Cmd.Process.Pid // the shell leads its own session
+		_ = syscall.Kill(-pgid, syscall.SIGHUP)
+		_ = syscall.Kill(-pgid, syscall.SIGCONT)
+
+		go func() {
+			select {
+			case <-s.done:
+			case <-time.After(killDelay):
+				_ = syscall.Kill(-pgid, syscall.SIGKILL)
+			}
+		}()
+	})
+}
+
+func (s *Session) closeTerminal() {
+	s.closePty.Do(func() { _ = s.Pty.Close() })
 }
@@ -0,0 +1,180 @@
+package terminal
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"pulse_agent/internal/config"
+)
+
+// PATH of every shell; the agent's own PATH is not passed on
+const shellPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// shellUser is the account a host shell runs as
+type shellUser struct {
+	name       string
+	home       string
+	uid        uint32
+	gid        uint32
+	groups     []uint32
+	switchUser bool // differs from the agent's user, needs credentials
+}
+
+// shellCommand builds a login shell for the configured user, with a
+// clean environment and no inherited privileges
+func shellCommand(cfg config.TerminalConfig) (*exec.Cmd, *shellUser, error) {
+	u, err := lookupShellUser(cfg.User, cfg.Group)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shell := cfg.Shell
+	if shell == "" {
+		shell = loginShell(u.name)
+	}
+	if shell == "" {
+		shell = "/bin/bash"
+		if _, err := os.Stat(shell); err != nil {
+			shell = "/bin/sh"
+		}
+	}
+
+	cmd := exec.Command(shell)
+	// A leading dash makes it a login shell, which reads the profile
+	cmd.Args[0] = "-" + filepath.Base(shell)
+	asUser(cmd, cfg, u, shell)
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+
+	return cmd, u, nil
+}
+
+// asUser sets up cmd to run in the working directory as the configured
+// user, with the sanitized environment
+func asUser(cmd *exec.Cmd, cfg config.TerminalConfig, u *shellUser, shell string) {
+	dir := cfg.WorkDir
+	if dir == "" {
+		dir = u.home
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		dir = "/"
+	}
+
+	cmd.Dir = dir
+	cmd.Env = shellEnv(cfg.Env, u, shell)
+	cmd.SysProcAttr = &syscall.SysProcAttr{}
+	if u.switchUser {
+		cmd.SysProcAttr.Credential = &syscall.Credential{
+			Uid:    u.uid,
+			Gid:    u.gid,
+			Groups: u.groups,
+		}
+	}
+}
+
+// shellEnv sets the login variables and copies only the allowlisted
+// ones from the agent's environment
+func shellEnv(allowlist []string, u *shellUser, shell string) []string {
+	env := []string{
+		"HOME=" + u.home,
+		"USER=" + u.name,
+		"LOGNAME=" + u.name,
+		"SHELL=" + shell,
+		"PATH=" + shellPath,
+		"TERM=xterm-256color",
+	}
+	for _, name := range allowlist {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+func lookupShellUser(name, group string) (*shellUser, error) {
+	var (
+		u   *user.User
+		err error
+	)
+	if name == "" {
+		u, err = user.Current()
+	} else if u, err = user.Lookup(name); err != nil {
+		u, err = user.LookupId(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("terminal user %q: %w", name, err)
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("terminal user %q: uid %s", u.Username, u.Uid)
+	}
+	gidStr := u.Gid
+	if group != "" {
+		g, err := user.LookupGroup(group)
+		if err != nil {
+			if g, err = user.LookupGroupId(group); err != nil {
+				return nil, fmt.Errorf("terminal group %q: %w", group, err)
+			}
+		}
+		gidStr = g.Gid
+	}
+	gid, err := strconv.ParseUint(gidStr, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("terminal group %q: gid %s", group, gidStr)
+	}
+
+	su := &shellUser{
+		name: u.Username,
+		home: u.HomeDir,
+		uid:  uint32(uid),
+		gid:  uint32(gid),
+	}
+	su.switchUser = su.uid != uint32(os.Getuid()) || su.gid != uint32(os.Getgid())
+	if !su.switchUser {
+		return su, nil
+	}
+
+	// Supplementary groups of the user only; an explicit group replaces
+	// the primary one. Without this the shell keeps the agent's groups.
+	su.groups = []uint32{su.gid}
+	if ids, err := u.GroupIds(); err == nil {
+		for _, id := range ids {
+			if g, err := strconv.ParseUint(id, 10, 32); err == nil && uint32(g) != su.gid {
+				su.groups = append(su.groups, uint32(g))
+			}
+		}
+	}
+	return su, nil
+}
+
+// loginShell reads the user's shell from /etc/passwd, which os/user
+// does not expose
+func loginShell(name string) string {
+	f, err := os.Open("/etc/passwd")
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ":")
+		if len(fields) == 7 && fields[0] == name {
+			shell := fields[6]
+			// nologin and false would end the session at once
+			if shell == "" || strings.HasSuffix(shell, "nologin") || strings.HasSuffix(shell, "false") {
+				return ""
+			}
+			return shell
+		}
+	}
+	return ""
+}
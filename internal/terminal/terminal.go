@@ -0,0 +1,20 @@
+package terminal
+
+// Terminal is an interactive session relayed over the agent WebSocket,
+// either a shell on the host or an exec session inside a container
+type Terminal interface {
+	Write(data []byte) error
+	// ReadLoop calls fn with the output until the session ends
+	ReadLoop(fn func([]byte))
+	Resize(rows, cols uint16) error
+	// Wait returns how the process ended; call it after ReadLoop returned
+	Wait() ExitStatus
+	Close()
+}
+
+// ExitStatus is how the process behind a terminal ended. Code is -1
+// when it was killed by Signal or its status is unknown.
+type ExitStatus struct {
+	Code   int    `json:"code"`
+	Signal string `json:"signal,omitempty"`
+}
@@ -2,38 +2,77 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"sync"
 
-	"github.com/creack/pty"
 	"github.com/gorilla/websocket"
 
+	"pulse_agent/internal/commands"
 	"pulse_agent/internal/config"
 	"pulse_agent/internal/terminal"
+	"pulse_agent/pkg/logger"
 )
 
+// Container actions running at once; more are refused
+const maxContainerActions = 4
+
 type Message struct {
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
 }
 
-func ConnectAgentWS(ctx context.Context, cfg *config.Config, serverUUID string) error {
+// agentConn serializes writes; gorilla/websocket allows one concurrent writer
+type agentConn struct {
+	*websocket.Conn
+	mu sync.Mutex
+}
+
+func (c *agentConn) send(msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.WriteJSON(msg)
+}
+
+// requestID reads the request_id of a message whose data could not be
+// decoded, so that the error can still be matched to the request
+func requestID(data interface{}) string {
+	var req struct {
+		RequestID string `json:"request_id"`
+	}
+	_ = decodeData(data, &req)
+	return req.RequestID
+}
+
+// decodeData converts the generic Data of a received message into v
+func decodeData(data interface{}, v interface{}) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+func ConnectAgentWS(ctx context.Context, cfg *config.Config, serverUUID string, actions *commands.Executor, recordings *terminal.Recordings) error {
 	header := http.Header{}
 	header.Set("x-api-key", cfg.APIKey)
 
 	wsURL := cfg.BackendURL
 	wsURL = "ws" + wsURL[4:] + "/ws/agent"
 
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
+	wsConn, _, err := websocket.DefaultDialer.Dial(wsURL, header)
 	if err != nil {
 		return err
 	}
-	defer conn.Close()
+	defer wsConn.Close()
+	conn := &agentConn{Conn: wsConn}
 
 	log.Println("Connected to backend WS")
 
 	// 🔐 Register agent
-	if err := conn.WriteJSON(Message{
+	if err := conn.send(Message{
 		Type: "agent:register",
 		Data: map[string]string{
 			"server_uuid": serverUUID,
@@ -41,25 +80,15 @@ func ConnectAgentWS(ctx context.Context, cfg *config.Config, serverUUID string)
 	}); err != nil {
 		return err
 	}
-	print(" registered the serverr")
+	log.Println("Agent registered")
 
-	session, err := terminal.StartShell()
-	print(" shell is started ", session)
+	// Terminals are started only when the backend opens a session
+	terminals := newTerminalSessions(conn, actions, recordings, cfg.Terminal)
+	defer terminals.closeAll()
+	runs := make(chan struct{}, cfg.Terminal.MaxSessions)
+	containerActions := make(chan struct{}, maxContainerActions)
 
-	if err != nil {
-		return err
-	}
-	defer session.Close()
-
-	// 📤 Shell stdout → backend
-	go session.ReadLoop(func(data []byte) {
-		_ = conn.WriteJSON(Message{
-			Type: "terminal:stdout",
-			Data: string(data),
-		})
-	})
-
-	// 📥 Backend → shell stdin
+	// 📥 Backend → agent
 	for {
 		var msg Message
 		if err := conn.ReadJSON(&msg); err != nil {
@@ -68,28 +97,108 @@ func ConnectAgentWS(ctx context.Context, cfg *config.Config, serverUUID string)
 
 		switch msg.Type {
 
+		case "terminal:open":
+			var req TerminalOpenRequest
+			if err := decodeData(msg.Data, &req); err != nil {
+				_ = conn.send(Message{
+					Type: "terminal:open:result",
+					Data: TerminalOpenResult{Error: "invalid request: " + err.Error()},
+				})
+				continue
+			}
+			terminals.open(ctx, req)
+
 		case "terminal:stdin":
-			text, ok := msg.Data.(string)
-			if ok {
-				_ = session.Write([]byte(text))
+			var data TerminalData
+			if err := decodeData(msg.Data, &data); err == nil {
+				terminals.write(data)
 			}
 
 		case "terminal:resize":
-			size, ok := msg.Data.(map[string]interface{})
-			if !ok {
+			var size TerminalResize
+			if err := decodeData(msg.Data, &size); err == nil {
+				terminals.resize(size)
+			}
+
+		case "terminal:close":
+			var session TerminalSession
+			if err := decodeData(msg.Data, &session); err == nil {
+				terminals.close(session.SessionID)
+			}
+
+		case "terminal:run":
+			var req commands.RunCommandRequest
+			if err := decodeData(msg.Data, &req); err != nil {
+				_ = conn.send(Message{
+					Type: "terminal:run:result",
+					Data: commands.RunCommandResult{Error: "invalid request: " + err.Error()},
+				})
+				continue
+			}
+
+			// As many commands as terminal sessions at a time
+			select {
+			case runs <- struct{}{}:
+			default:
+				logger.Warn("Refused command %s requested by %s: too many running", req.Command, req.RequestedBy)
+				_ = conn.send(Message{
+					Type: "terminal:run:result",
+					Data: commands.RunCommandResult{
+						RequestID: req.RequestID,
+						Command:   req.Command,
+						Args:      req.Args,
+						ExitCode:  -1,
+						Error:     fmt.Sprintf("too many commands running (max %d)", cap(runs)),
+					},
+				})
+				continue
+			}
+
+			go func() {
+				defer func() { <-runs }()
+				_ = conn.send(Message{
+					Type: "terminal:run:result",
+					Data: actions.RunCommand(ctx, req),
+				})
+			}()
+
+		case "container:action":
+			var req commands.ContainerActionRequest
+			if err := decodeData(msg.Data, &req); err != nil {
+				_ = conn.send(Message{
+					Type: "container:action:result",
+					Data: commands.ContainerActionResult{
+						RequestID: requestID(msg.Data),
+						Error:     "invalid request: " + err.Error(),
+					},
+				})
 				continue
 			}
 
-			rows, rOk := size["rows"].(float64)
-			cols, cOk := size["cols"].(float64)
-			if !rOk || !cOk {
+			select {
+			case containerActions <- struct{}{}:
+			default:
+				logger.Warn("Refused %s of %s requested by %s: too many actions running", req.Action, req.Container, req.RequestedBy)
+				_ = conn.send(Message{
+					Type: "container:action:result",
+					Data: commands.ContainerActionResult{
+						RequestID: req.RequestID,
+						Action:    req.Action,
+						Container: req.Container,
+						Error:     fmt.Sprintf("too many container actions running (max %d)", cap(containerActions)),
+					},
+				})
 				continue
 			}
 
-			_ = pty.Setsize(session.Pty, &pty.Winsize{
-				Rows: uint16(rows),
-				Cols: uint16(cols),
-			})
+			// Stop and restart can take a while, keep reading meanwhile
+			go func() {
+				defer func() { <-containerActions }()
+				_ = conn.send(Message{
+					Type: "container:action:result",
+					Data: actions.ContainerAction(ctx, req),
+				})
+			}()
 		}
 	}
 }
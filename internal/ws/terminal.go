@@ -0,0 +1,338 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"pulse_agent/internal/commands"
+	"pulse_agent/internal/config"
+	"pulse_agent/internal/terminal"
+	"pulse_agent/pkg/logger"
+)
+
+// TerminalOpenRequest is sent by the backend as "terminal:open". Without
+// a container the session is a shell on the host.
+type TerminalOpenRequest struct {
+	SessionID   string `json:"session_id"`
+	Container   string `json:"container,omitempty"` // name or ID
+	Rows        uint16 `json:"rows,omitempty"`
+	Cols        uint16 `json:"cols,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"`
+}
+
+// TerminalOpenResult is sent back as "terminal:open:result"
+type TerminalOpenResult struct {
+	SessionID     string `json:"session_id"`
+	OK            bool   `json:"ok"`
+	Denied        bool   `json:"denied,omitempty"`
+	Error         string `json:"error,omitempty"`
+	ContainerID   string `json:"container_id,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+}
+
+// TerminalData carries "terminal:stdin" and "terminal:stdout"
+type TerminalData struct {
+	SessionID string `json:"session_id"`
+	Data      string `json:"data"`
+}
+
+// TerminalResize is sent by the backend as "terminal:resize"
+type TerminalResize struct {
+	SessionID string `json:"session_id"`
+	Rows      uint16 `json:"rows"`
+	Cols      uint16 `json:"cols"`
+}
+
+// TerminalSession identifies the session of "terminal:close"
+type TerminalSession struct {
+	SessionID string `json:"session_id"`
+}
+
+// Why a session ended, in terminal:exit
+const (
+	exitReasonExited       = "exited" // the shell exited by itself
+	exitReasonClosed       = "closed" // terminal:close
+	exitReasonIdle         = "idle_timeout"
+	exitReasonMaxDuration  = "max_duration"
+	exitReasonDisconnected = "disconnected"
+)
+
+// TerminalExit is sent as "terminal:exit" when a session ended
+type TerminalExit struct {
+	SessionID string `json:"session_id"`
+	Reason    string `json:"reason"`
+	terminal.ExitStatus
+}
+
+// TerminalWarning is sent as "terminal:warning" before a timeout closes
+// a session
+type TerminalWarning struct {
+	SessionID string    `json:"session_id"`
+	Reason    string    `json:"reason"` // idle_timeout | max_duration
+	ClosesAt  time.Time `json:"closes_at"`
+	Message   string    `json:"message"`
+}
+
+// terminalSessions multiplexes the terminals of one agent WebSocket,
+// keyed by the session ID the backend picked in terminal:open. Nothing
+// is started until a session is opened.
+type terminalSessions struct {
+	conn       *agentConn
+	actions    *commands.Executor
+	recordings *terminal.Recordings // nil when recording is disabled
+	cfg        config.TerminalConfig
+
+	mu       sync.Mutex
+	sessions map[string]*terminalSession
+}
+
+type terminalSession struct {
+	id        string
+	started   time.Time
+	lastInput atomic.Int64 // unix nanoseconds
+
+	mu     sync.Mutex
+	term   terminal.Terminal // nil while starting
+	closed bool
+	reason string // why it was closed
+}
+
+func newTerminalSessions(conn *agentConn, actions *commands.Executor, recordings *terminal.Recordings, cfg config.TerminalConfig) *terminalSessions {
+	return &terminalSessions{
+		conn:       conn,
+		actions:    actions,
+		recordings: recordings,
+		cfg:        cfg,
+		sessions:   make(map[string]*terminalSession),
+	}
+}
+
+// open reserves the session ID, then starts the terminal in the
+// background; an exec session needs a few round trips to the daemon
+func (t *terminalSessions) open(ctx context.Context, req TerminalOpenRequest) {
+	// Restricted mode only runs allowlisted commands, see terminal:run
+	if t.cfg.Mode != config.TerminalShell {
+		logger.Warn("Refused terminal session %s requested by %s: terminal mode is %s", req.SessionID, req.RequestedBy, t.cfg.Mode)
+		_ = t.conn.send(Message{
+			Type: "terminal:open:result",
+			Data: TerminalOpenResult{
+				SessionID: req.SessionID,
+				Denied:    true,
+				Error:     "interactive terminals not allowed by agent policy (mode " + t.cfg.Mode + ")",
+			},
+		})
+		return
+	}
+
+	s, err := t.reserve(req.SessionID)
+	if err != nil {
+		_ = t.conn.send(Message{
+			Type: "terminal:open:result",
+			Data: TerminalOpenResult{SessionID: req.SessionID, Error: err.Error()},
+		})
+		return
+	}
+
+	go func() {
+		term, result := t.start(ctx, req)
+		if term != nil {
+			term, result = t.record(term, req, result)
+		}
+		_ = t.conn.send(Message{Type: "terminal:open:result", Data: result})
+		if term == nil {
+			t.remove(s)
+			return
+		}
+
+		// Unless it was closed while starting
+		if s.attach(term) {
+			done := make(chan struct{})
+			go t.watch(s, done)
+
+			term.ReadLoop(func(data []byte) {
+				_ = t.conn.send(Message{
+					Type: "terminal:stdout",
+					Data: TerminalData{SessionID: s.id, Data: string(data)},
+				})
+			})
+			close(done)
+		}
+
+		status := term.Wait()
+		t.remove(s)
+		reason := s.close(exitReasonExited)
+		logger.Info("Terminal session %s ended: %s, code %d %s", s.id, reason, status.Code, status.Signal)
+		_ = t.conn.send(Message{
+			Type: "terminal:exit",
+			Data: TerminalExit{SessionID: s.id, Reason: reason, ExitStatus: status},
+		})
+	}()
+}
+
+func (t *terminalSessions) reserve(id string) (*terminalSession, error) {
+	if id == "" {
+		return nil, errors.New("no session_id given")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.sessions[id]; ok {
+		return nil, fmt.Errorf("session %s is already open", id)
+	}
+	if len(t.sessions) >= t.cfg.MaxSessions {
+		return nil, fmt.Errorf("too many terminal sessions (max %d)", t.cfg.MaxSessions)
+	}
+
+	s := &terminalSession{id: id, started: time.Now()}
+	s.lastInput.Store(s.started.UnixNano())
+	t.sessions[id] = s
+	return s, nil
+}
+
+func (t *terminalSessions) start(ctx context.Context, req TerminalOpenRequest) (terminal.Terminal, TerminalOpenResult) {
+	result := TerminalOpenResult{SessionID: req.SessionID}
+
+	if req.Container != "" {
+		term, exec := t.actions.ContainerExec(ctx, commands.ContainerExecRequest{
+			RequestID:   req.SessionID,
+			Container:   req.Container,
+			Rows:        req.Rows,
+			Cols:        req.Cols,
+			RequestedBy: req.RequestedBy,
+		})
+		result.OK = exec.OK
+		result.Denied = exec.Denied
+		result.Error = exec.Error
+		result.ContainerID = exec.ContainerID
+		result.ContainerName = exec.ContainerName
+		return term, result
+	}
+
+	shell, err := terminal.StartShell(t.cfg, req.Rows, req.Cols)
+	if err != nil {
+		logger.Warn("Failed to start terminal session %s: %v", req.SessionID, err)
+		result.Error = err.Error()
+		return nil, result
+	}
+	logger.Info("Terminal session %s opened", req.SessionID)
+	result.OK = true
+	return shell, result
+}
+
+// record wraps a started terminal in a recording; a session that cannot
+// be recorded is not opened
+func (t *terminalSessions) record(term terminal.Terminal, req TerminalOpenRequest, result TerminalOpenResult) (terminal.Terminal, TerminalOpenResult) {
+	recorded, err := t.recordings.Record(term, terminal.RecordingInfo{
+		SessionID:   req.SessionID,
+		RequestedBy: req.RequestedBy,
+		Container:   req.Container,
+		Rows:        req.Rows,
+		Cols:        req.Cols,
+	})
+	if err != nil {
+		logger.Warn("Failed to record terminal session %s: %v", req.SessionID, err)
+		term.Close()
+		result.OK = false
+		result.Error = "recording failed: " + err.Error()
+		return nil, result
+	}
+	return recorded, result
+}
+
+func (t *terminalSessions) get(id string) *terminalSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sessions[id]
+}
+
+func (t *terminalSessions) remove(s *terminalSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions[s.id] == s {
+		delete(t.sessions, s.id)
+	}
+}
+
+func (t *terminalSessions) write(msg TerminalData) {
+	if s := t.get(msg.SessionID); s != nil {
+		s.lastInput.Store(time.Now().UnixNano())
+		if term := s.terminal(); term != nil {
+			_ = term.Write([]byte(msg.Data))
+		}
+	}
+}
+
+func (t *terminalSessions) resize(msg TerminalResize) {
+	if msg.Rows == 0 || msg.Cols == 0 {
+		return
+	}
+	if s := t.get(msg.SessionID); s != nil {
+		if term := s.terminal(); term != nil {
+			_ = term.Resize(msg.Rows, msg.Cols)
+		}
+	}
+}
+
+// close ends a session; its terminal:exit is sent once the output
+// relay stops
+func (t *terminalSessions) close(id string) {
+	if s := t.get(id); s != nil {
+		s.close(exitReasonClosed)
+	}
+}
+
+// closeAll ends every session when the connection goes away
+func (t *terminalSessions) closeAll() {
+	t.mu.Lock()
+	sessions := make([]*terminalSession, 0, len(t.sessions))
+	for _, s := range t.sessions {
+		sessions = append(sessions, s)
+	}
+	t.mu.Unlock()
+
+	for _, s := range sessions {
+		s.close(exitReasonDisconnected)
+	}
+}
+
+// attach sets the started terminal, or closes it and returns false if
+// the session was closed meanwhile
+func (s *terminalSession) attach(term terminal.Terminal) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		term.Close()
+		return false
+	}
+	s.term = term
+	return true
+}
+
+func (s *terminalSession) terminal() terminal.Terminal {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.term
+}
+
+// close ends the session for reason and returns the reason it was
+// first closed for
+func (s *terminalSession) close(reason string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return s.reason
+	}
+	s.closed = true
+	s.reason = reason
+	if s.term != nil {
+		s.term.Close()
+	}
+	return reason
+}
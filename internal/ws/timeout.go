@@ -0,0 +1,94 @@
+package ws
+
+import (
+	"fmt"
+	"time"
+)
+
+// watch closes a session after TerminalConfig.IdleTimeout without input
+// or after MaxDuration, and warns the backend TimeoutWarning ahead. It
+// returns when done is closed.
+func (t *terminalSessions) watch(s *terminalSession, done <-chan struct{}) {
+	idle, max, warn := t.cfg.IdleTimeout, t.cfg.MaxDuration, t.cfg.TimeoutWarning
+	if idle == 0 && max == 0 {
+		return
+	}
+
+	var (
+		maxDeadline time.Time
+		warnedMax   bool
+		warnedIdle  time.Time // idle deadline already warned about
+	)
+	if max > 0 {
+		maxDeadline = s.started.Add(max)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		// Input moves the idle deadline; a wakeup for an older one
+		// finds nothing due and sleeps again
+		var idleDeadline time.Time
+		if idle > 0 {
+			idleDeadline = time.Unix(0, s.lastInput.Load()).Add(idle)
+		}
+
+		switch {
+		case !maxDeadline.IsZero() && !now.Before(maxDeadline):
+			s.close(exitReasonMaxDuration)
+			return
+		case !idleDeadline.IsZero() && !now.Before(idleDeadline):
+			s.close(exitReasonIdle)
+			return
+		}
+
+		next := time.Duration(1<<63 - 1)
+		if !maxDeadline.IsZero() {
+			if !warnedMax && !now.Before(maxDeadline.Add(-warn)) {
+				warnedMax = true
+				t.warn(s, exitReasonMaxDuration, maxDeadline,
+					"Session closes in %s: maximum session duration reached", maxDeadline.Sub(now))
+			}
+			next = min(next, untilWarning(now, maxDeadline, warn, warnedMax))
+		}
+		if !idleDeadline.IsZero() {
+			if !warnedIdle.Equal(idleDeadline) && !now.Before(idleDeadline.Add(-warn)) {
+				warnedIdle = idleDeadline
+				t.warn(s, exitReasonIdle, idleDeadline,
+					"Session closes in %s due to inactivity", idleDeadline.Sub(now))
+			}
+			next = min(next, untilWarning(now, idleDeadline, warn, warnedIdle.Equal(idleDeadline)))
+		}
+
+		timer.Reset(next)
+	}
+}
+
+// untilWarning is the time until the warning for deadline is due, or
+// until the deadline once warned
+func untilWarning(now, deadline time.Time, warn time.Duration, warned bool) time.Duration {
+	if warned {
+		return deadline.Sub(now)
+	}
+	return deadline.Add(-warn).Sub(now)
+}
+
+func (t *terminalSessions) warn(s *terminalSession, reason string, closesAt time.Time, format string, left time.Duration) {
+	_ = t.conn.send(Message{
+		Type: "terminal:warning",
+		Data: TerminalWarning{
+			SessionID: s.id,
+			Reason:    reason,
+			ClosesAt:  closesAt,
+			Message:   fmt.Sprintf(format, left.Round(time.Second)),
+		},
+	})
+}